@@ -0,0 +1,59 @@
+// Command wordle-precompute writes the solution shortlist for every opener and achievable feedback
+// to a file, which the server can load into its cache at startup with -warm
+package main
+
+import (
+	"flag"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/sam-bee/wordle-game-engine/pkg/wordlegameengine"
+)
+
+func main() {
+	dataDir := flag.String("data", "", "directory containing the wordlists, default the bundled lists")
+	outPath := flag.String("out", "first-turns.bin", "file to write the first-turn shortlists to")
+	openersFlag := flag.String("openers", "", "comma-separated openers to precompute, default all allowed guesses")
+	flag.Parse()
+
+	report, err := wordlegameengine.LoadWordlistsDir(*dataDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if !report.OK() {
+		log.Printf("wordlists: %s", report)
+	}
+
+	openers, err := parseOpeners(*openersFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	start := time.Now()
+	n, err := wordlegameengine.SaveFirstTurns(*outPath, openers)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("wrote %d shortlists for %d openers to %s in %v", n, len(openers), *outPath, time.Since(start).Round(time.Millisecond))
+}
+
+// parseOpeners parses a comma-separated list of openers, or returns every allowed guess if empty
+func parseOpeners(s string) ([]wordlegameengine.Word, error) {
+	if s == "" {
+		return wordlegameengine.AllowedGuesses, nil
+	}
+
+	var openers []wordlegameengine.Word
+	for _, field := range strings.Split(s, ",") {
+		opener, err := wordlegameengine.NewWord(strings.TrimSpace(field))
+		if err != nil {
+			return nil, err
+		}
+		if err := opener.Validate(); err != nil {
+			return nil, err
+		}
+		openers = append(openers, opener)
+	}
+	return openers, nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/sam-bee/wordle-game-engine/pkg/wordlegameengine"
+)
+
+func TestMain(m *testing.M) {
+	if err := wordlegameengine.LoadDefaultWordlists(); err != nil {
+		fmt.Printf("Failed to load wordlists: %v\n", err)
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+func TestParseOpeners(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{"default all guesses", "", nil, false},
+		{"list", "raise, crane", []string{"raise", "crane"}, false},
+		{"invalid word", "raise,abcde", nil, true},
+		{"wrong length", "rais", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			openers, err := parseOpeners(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseOpeners(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if tt.want == nil {
+				if len(openers) != len(wordlegameengine.AllowedGuesses) {
+					t.Errorf("parseOpeners(%q) returned %d openers, want all %d", tt.input, len(openers), len(wordlegameengine.AllowedGuesses))
+				}
+				return
+			}
+			if len(openers) != len(tt.want) {
+				t.Fatalf("parseOpeners(%q) = %v, want %v", tt.input, openers, tt.want)
+			}
+			for i, w := range tt.want {
+				if openers[i].String() != w {
+					t.Errorf("opener %d = %q, want %q", i, openers[i].String(), w)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,10 @@
+// Package data bundles the standard wordlists, so that binaries and library users do not need to
+// ship the data directory
+package data
+
+import "embed"
+
+// FS holds allowed-guesses.txt and allowed-solutions.txt
+//
+//go:embed allowed-guesses.txt allowed-solutions.txt
+var FS embed.FS
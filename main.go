@@ -1,18 +1,39 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"github.com/sam-bee/wordle-game-engine/pkg/wordlegameengine"
+	"github.com/sam-bee/wordle-game-engine/pkg/wordlesolver"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"runtime"
+	"sync"
+	"syscall"
+	"time"
 )
 
 // Request struct for /api/evaluate endpoint
 type Request struct {
-	Solution      string `json:"solution"`
-	Turns         []Turn `json:"turns"`
-	ProposedGuess string `json:"proposed_guess"`
+	Solution      string        `json:"solution"`
+	Turns         []Turn        `json:"turns"`
+	ProposedGuess string        `json:"proposed_guess"`
+	HardMode      bool          `json:"hard_mode"` // Shorthand for rules.hard_mode
+	Rules         *RulesRequest `json:"rules"`     // Standard rules if omitted
+}
+
+// RulesRequest sets the rules a request's turns and proposed guess are checked against. Omitted
+// fields keep the standard rules
+type RulesRequest struct {
+	MaxGuesses   int    `json:"max_guesses"` // 0 means the standard 6, -1 means no limit
+	HardMode     bool   `json:"hard_mode"`
+	GuessList    string `json:"guess_list"` // "guesses" (the default) or "solutions"
+	AllowRepeats bool   `json:"allow_repeats"`
 }
 
 type Turn struct {
@@ -22,168 +43,788 @@ type Turn struct {
 
 // Response struct for /api/evaluate endpoint
 type Response struct {
-	GameStatus         string `json:"game_status"`
-	TurnValid          bool   `json:"turn_valid"`
-	ShortlistReduction struct {
-		Before int     `json:"before"`
-		After  int     `json:"after"`
-		Ratio  float64 `json:"ratio"`
-	} `json:"shortlist_reduction"`
-	Feedback string `json:"feedback"`
+	GameStatus         string             `json:"game_status"`
+	TurnValid          bool               `json:"turn_valid"`
+	ShortlistReduction ShortlistReduction `json:"shortlist_reduction"`
+	Feedback           string             `json:"feedback"`
+	InvalidReason      string             `json:"invalid_reason,omitempty"`
 }
 
-func evaluateHandler(w http.ResponseWriter, r *http.Request) {
+// gameRules returns the rules of a request, with hard mode also set by the top-level hard_mode
+func (req Request) gameRules() (wordlegameengine.Rules, error) {
+	rules := wordlegameengine.DefaultRules()
+	if req.Rules != nil {
+		rules.HardMode = req.Rules.HardMode
+		rules.AllowRepeats = req.Rules.AllowRepeats
+		if req.Rules.MaxGuesses != 0 {
+			rules.MaxGuesses = req.Rules.MaxGuesses
+		}
+		if req.Rules.GuessList != "" {
+			guessList, err := wordlegameengine.ParseGuessList(req.Rules.GuessList)
+			if err != nil {
+				return wordlegameengine.Rules{}, err
+			}
+			rules.GuessList = guessList
+		}
+	}
+	rules.HardMode = rules.HardMode || req.HardMode
+	return rules, rules.Validate()
+}
+
+// BatchItem is the result of one request of an /api/evaluate/batch body: the Response it gets from
+// /api/evaluate, or the error it would have been given there
+type BatchItem struct {
+	*Response
+	Error *ErrorDetail `json:"error,omitempty"`
+}
+
+// ShortlistReduction reports how much the proposed guess narrowed a shortlist
+type ShortlistReduction struct {
+	Before int     `json:"before"`
+	After  int     `json:"after"`
+	Ratio  float64 `json:"ratio"`
+}
+
+// MultiRequest struct for /api/evaluate/multi endpoint. Past turns are given as guesses only, as
+// each board's feedback follows from its solution
+type MultiRequest struct {
+	Solutions     []string `json:"solutions"`
+	Guesses       []string `json:"guesses"`
+	ProposedGuess string   `json:"proposed_guess"`
+	MaxGuesses    int      `json:"max_guesses"` // 0 means the standard budget for the number of boards, -1 means no limit
+}
+
+// MultiResponse struct for /api/evaluate/multi endpoint
+type MultiResponse struct {
+	GameStatus       string          `json:"game_status"`
+	TurnValid        bool            `json:"turn_valid"`
+	InvalidReason    string          `json:"invalid_reason,omitempty"`
+	GuessesRemaining int             `json:"guesses_remaining"` // -1 if there is no limit
+	Boards           []BoardResponse `json:"boards"`
+}
+
+// BoardResponse is the state of one board of a multi-board game after the proposed guess
+type BoardResponse struct {
+	Solved             bool               `json:"solved"`
+	Feedback           string             `json:"feedback,omitempty"` // Empty if the board was solved before the guess
+	ShortlistReduction ShortlistReduction `json:"shortlist_reduction"`
+}
+
+// AssistRequest struct for /api/assist endpoint: turns from a live game whose solution is unknown
+type AssistRequest struct {
+	Turns []Turn `json:"turns"`
+}
+
+// AssistResponse struct for /api/assist endpoint
+type AssistResponse struct {
+	GameStatus string   `json:"game_status"`
+	Count      int      `json:"count"`
+	Candidates []string `json:"candidates"`
+}
+
+// SuggestRequest struct for /api/suggest endpoint. The solution is optional: without it the
+// turns are replayed in assistant mode. The strategy defaults to "entropy"
+type SuggestRequest struct {
+	Solution string `json:"solution"`
+	Turns    []Turn `json:"turns"`
+	TopN     int    `json:"top_n"`
+	Strategy string `json:"strategy"`
+}
+
+// SuggestResponse struct for /api/suggest endpoint
+type SuggestResponse struct {
+	Strategy       string       `json:"strategy"`
+	ShortlistCount int          `json:"shortlist_count"`
+	Suggestions    []Suggestion `json:"suggestions"`
+}
+
+// CacheStatsResponse struct for /api/cache/stats endpoint
+type CacheStatsResponse struct {
+	Hits         uint64 `json:"hits"`
+	Misses       uint64 `json:"misses"`
+	Deduplicated uint64 `json:"deduplicated"`
+	Evictions    uint64 `json:"evictions"`
+	Entries      int    `json:"entries"`
+	Bytes        int64  `json:"bytes"`
+	MaxBytes     int64  `json:"max_bytes"`
+}
+
+type Suggestion struct {
+	Guess       string  `json:"guess"`
+	Score       float64 `json:"score"`
+	InShortlist bool    `json:"in_shortlist"`
+}
+
+// defaultTopN is the number of suggestions returned when a request does not set top_n
+const defaultTopN = 10
+
+// maxBatchSize bounds the number of requests in an /api/evaluate/batch body
+const maxBatchSize = 1000
+
+// maxBatchItemBytes is the body size allowed per request of a batch, far more than a game's turns
+// need. Larger bodies are rejected while being read
+const maxBatchItemBytes = 16 << 10
+
+// batchWorkers is the number of requests of a batch evaluated at once, set by -batch-workers
+var batchWorkers = runtime.GOMAXPROCS(0)
+
+// ErrorResponse is the JSON body of every non-200 response
+type ErrorResponse struct {
+	Error ErrorDetail `json:"error"`
+}
+
+type ErrorDetail struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Field   string `json:"field,omitempty"`
+	Turn    *int   `json:"turn,omitempty"` // Index of the failing turn in Request.Turns
+}
+
+// Codes reported in Response.InvalidReason and ErrorDetail.Code
+const (
+	ReasonWrongLength   = "wrong_length"
+	ReasonBadCharacters = "bad_characters"
+	ReasonNotInWordlist = "not_in_wordlist"
+	ReasonRepeatedGuess = "repeated_guess"
+	ReasonGameOver      = "game_over"
+	ReasonHardMode      = "hard_mode_violation"
+
+	CodeFeedbackMismatch   = "feedback_mismatch"
+	CodeContradictoryTurns = "contradictory_turns"
+
+	CodeUnknownStrategy = "unknown_strategy"
+
+	CodeInvalidBoards     = "invalid_boards"
+	CodeInvalidMaxGuesses = "invalid_max_guesses"
+	CodeInvalidRules      = "invalid_rules"
+
+	CodeBatchTooLarge = "batch_too_large"
+	CodeBodyTooLarge  = "body_too_large"
+
+	CodeMethodNotAllowed = "method_not_allowed"
+	CodeInvalidJSON      = "invalid_json"
+	CodeInvalidRequest   = "invalid_request"
+)
+
+// apiError is an error response: the HTTP status and the JSON body describing it
+type apiError struct {
+	Status int
+	Detail ErrorDetail
+}
+
+// newAPIError builds a 400 response for an engine error, reporting the field it concerns
+func newAPIError(err error) *apiError {
+	detail := ErrorDetail{
+		Code:    errorCode(err),
+		Message: err.Error(),
+	}
+	var validationErr *wordlegameengine.ValidationError
+	if errors.As(err, &validationErr) {
+		detail.Field = validationErr.Field
+	}
+	return &apiError{Status: http.StatusBadRequest, Detail: detail}
+}
+
+// newTurnError builds a 400 response for an error in the past turn at index turn
+func newTurnError(turn int, err error) *apiError {
+	var turnErr *wordlegameengine.TurnError
+	if !errors.As(err, &turnErr) {
+		err = fmt.Errorf("turn %d: %w", turn, err)
+	}
+	apiErr := newAPIError(err)
+	apiErr.Detail.Turn = &turn
+	return apiErr
+}
+
+// errorCode maps an engine error to its machine-readable code
+func errorCode(err error) string {
+	switch {
+	case errors.Is(err, wordlegameengine.ErrInvalidLength):
+		return ReasonWrongLength
+	case errors.Is(err, wordlegameengine.ErrInvalidCharacter):
+		return ReasonBadCharacters
+	case errors.Is(err, wordlegameengine.ErrNotInWordlist):
+		return ReasonNotInWordlist
+	case errors.Is(err, wordlegameengine.ErrRepeatedGuess):
+		return ReasonRepeatedGuess
+	case errors.Is(err, wordlegameengine.ErrGameOver):
+		return ReasonGameOver
+	case errors.Is(err, wordlegameengine.ErrHardModeViolation):
+		return ReasonHardMode
+	case errors.Is(err, wordlegameengine.ErrFeedbackMismatch):
+		return CodeFeedbackMismatch
+	case errors.Is(err, wordlegameengine.ErrContradictoryTurns):
+		return CodeContradictoryTurns
+	case errors.Is(err, wordlesolver.ErrUnknownStrategy):
+		return CodeUnknownStrategy
+	case errors.Is(err, wordlegameengine.ErrInvalidBoards):
+		return CodeInvalidBoards
+	case errors.Is(err, wordlegameengine.ErrInvalidMaxGuesses):
+		return CodeInvalidMaxGuesses
+	case errors.Is(err, wordlegameengine.ErrInvalidRules):
+		return CodeInvalidRules
+	default:
+		return CodeInvalidRequest
+	}
+}
+
+func writeError(w http.ResponseWriter, apiErr *apiError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(apiErr.Status)
+	json.NewEncoder(w).Encode(ErrorResponse{Error: apiErr.Detail})
+}
+
+// decodeRequest decodes the JSON body of a POST request into req. It writes an error response
+// and returns false if the method or body is invalid
+func decodeRequest(w http.ResponseWriter, r *http.Request, req any) bool {
 	if r.Method != "POST" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
+		writeError(w, &apiError{
+			Status: http.StatusMethodNotAllowed,
+			Detail: ErrorDetail{Code: CodeMethodNotAllowed, Message: "Method not allowed"},
+		})
+		return false
 	}
 
 	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(req); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeError(w, &apiError{
+				Status: http.StatusRequestEntityTooLarge,
+				Detail: ErrorDetail{Code: CodeBodyTooLarge, Message: fmt.Sprintf("Body larger than %d bytes", maxBytesErr.Limit)},
+			})
+			return false
+		}
+		writeError(w, &apiError{
+			Status: http.StatusBadRequest,
+			Detail: ErrorDetail{Code: CodeInvalidJSON, Message: "Invalid JSON"},
+		})
+		return false
+	}
+	return true
+}
+
+// parseTurn parses and validates the past turn at index i of a request
+func parseTurn(i int, turn Turn) (wordlegameengine.Word, wordlegameengine.Feedback, *apiError) {
+	guess, err := wordlegameengine.NewWord(turn.Guess)
+	if err != nil {
+		return wordlegameengine.Word{}, wordlegameengine.Feedback{}, newTurnError(i, err)
+	}
+	if err := guess.Validate(); err != nil {
+		return wordlegameengine.Word{}, wordlegameengine.Feedback{}, newTurnError(i, err)
+	}
+	feedback, err := wordlegameengine.ParseFeedback(turn.Feedback)
+	if err != nil {
+		return wordlegameengine.Word{}, wordlegameengine.Feedback{}, newTurnError(i, err)
+	}
+	return guess, feedback, nil
+}
+
+func evaluateHandler(w http.ResponseWriter, r *http.Request) {
 	var req Request
-	if err := decoder.Decode(&req); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+	if !decodeRequest(w, r, &req) {
 		return
 	}
 
+	resp, apiErr := evaluate(req)
+	if apiErr != nil {
+		writeError(w, apiErr)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// evaluate replays the past turns of a request and plays its proposed guess
+func evaluate(req Request) (Response, *apiError) {
 	// Validate solution
 	sol, err := wordlegameengine.NewSolution(req.Solution)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
+		return Response{}, newAPIError(err)
 	}
 	if err := sol.Validate(); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
+		return Response{}, newAPIError(err)
+	}
+
+	rules, err := req.gameRules()
+	if err != nil {
+		return Response{}, newAPIError(err)
 	}
 
-	// Validate proposed guess
+	game, apiErr := replayTurns(sol, req.Turns, rules)
+	if apiErr != nil {
+		return Response{}, apiErr
+	}
+
+	// Get shortlist length BEFORE playing proposed guess
+	before := game.ShortlistLength()
+
+	// An invalid proposed guess is not played: it is reported with turn_valid=false and a reason
+	feedbackStr := ""
+	after := before // If no proposed guess, after = before (no reduction)
+	status := game.Status()
+	turnValid := true
+	reason := ""
 	if req.ProposedGuess != "" {
 		guess, err := wordlegameengine.NewWord(req.ProposedGuess)
+		if err == nil {
+			err = game.ValidateGuess(guess)
+		}
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-			return
+			turnValid = false
+			reason = errorCode(err)
+			if errors.Is(err, wordlegameengine.ErrGameOver) {
+				status = wordlegameengine.StatusAlreadyFinished
+			}
+		} else {
+			feedback := sol.CheckGuess(guess)
+			feedbackStr = feedback.String()
+			game.PlayGuess(guess)
+			after = game.ShortlistLength()
+			status = game.Status()
 		}
-		if err := guess.Validate(); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-			return
+	}
+
+	resp := Response{
+		GameStatus:    status.String(),
+		TurnValid:     turnValid,
+		InvalidReason: reason,
+		Feedback:      feedbackStr,
+	}
+	resp.ShortlistReduction = newShortlistReduction(before, after)
+
+	return resp, nil
+}
+
+func evaluateBatchHandler(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBatchSize*maxBatchItemBytes)
+	var reqs []Request
+	if !decodeRequest(w, r, &reqs) {
+		return
+	}
+	if len(reqs) > maxBatchSize {
+		writeError(w, &apiError{
+			Status: http.StatusBadRequest,
+			Detail: ErrorDetail{
+				Code:    CodeBatchTooLarge,
+				Message: fmt.Sprintf("batch of %d requests, at most %d allowed", len(reqs), maxBatchSize),
+			},
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(evaluateBatch(reqs))
+}
+
+// evaluateBatch evaluates each request as /api/evaluate would, batchWorkers at a time. Every
+// request gets an item at its own index, holding its error if it failed, so one bad request does
+// not fail the others. The workers share the turn cache, as concurrent /api/evaluate requests do
+func evaluateBatch(reqs []Request) []BatchItem {
+	items := make([]BatchItem, len(reqs))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(max(batchWorkers, 1), len(reqs)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				resp, apiErr := evaluate(reqs[i])
+				if apiErr != nil {
+					items[i].Error = &apiErr.Detail
+					continue
+				}
+				items[i].Response = &resp
+			}
+		}()
+	}
+	for i := range reqs {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return items
+}
+
+// newShortlistReduction reports a shortlist going from before to after candidates
+func newShortlistReduction(before, after int) ShortlistReduction {
+	// Calculate ratio (handle division by zero)
+	ratio := 0.0
+	if before > 0 {
+		ratio = 1.0 - (float64(after) / float64(before))
+	}
+	return ShortlistReduction{Before: before, After: after, Ratio: ratio}
+}
+
+func evaluateMultiHandler(w http.ResponseWriter, r *http.Request) {
+	var req MultiRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	resp, apiErr := evaluateMulti(req)
+	if apiErr != nil {
+		writeError(w, apiErr)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// evaluateMulti replays the past guesses of a multi-board request and plays its proposed guess
+func evaluateMulti(req MultiRequest) (MultiResponse, *apiError) {
+	solutions := make([]wordlegameengine.Solution, len(req.Solutions))
+	for i, s := range req.Solutions {
+		sol, err := wordlegameengine.NewSolution(s)
+		if err != nil {
+			return MultiResponse{}, newAPIError(fmt.Errorf("board %d: %w", i, err))
 		}
+		solutions[i] = sol
+	}
+	game, err := wordlegameengine.NewMultiGame(solutions, req.MaxGuesses)
+	if err != nil {
+		return MultiResponse{}, newAPIError(err)
 	}
 
-	// Check for first turn cache
-	var cacheKey wordlegameengine.CacheKey
-	haveFirstTurn := len(req.Turns) > 0
-	cached := false
-	var cachedShortlist []wordlegameengine.Word
+	// Past guesses must be valid and played before the game ended
+	for i, s := range req.Guesses {
+		guess, err := wordlegameengine.NewWord(s)
+		if err == nil {
+			err = game.PlayGuess(guess)
+		}
+		if err != nil {
+			return MultiResponse{}, newTurnError(i, err)
+		}
+	}
 
-	if haveFirstTurn {
-		firstGuess, err := wordlegameengine.NewWord(req.Turns[0].Guess)
+	before := make([]int, len(game.Boards))
+	wasSolved := make([]bool, len(game.Boards))
+	for i, board := range game.Boards {
+		before[i] = board.ShortlistLength()
+		wasSolved[i] = game.Solved(i)
+	}
+
+	// An invalid proposed guess is not played: it is reported with turn_valid=false and a reason
+	status := game.Status()
+	turnValid := true
+	reason := ""
+	played := false
+	if req.ProposedGuess != "" {
+		guess, err := wordlegameengine.NewWord(req.ProposedGuess)
+		if err == nil {
+			err = game.ValidateGuess(guess)
+		}
 		if err != nil {
-			http.Error(w, fmt.Errorf("invalid past guess %q: %w", req.Turns[0].Guess, err).Error(), http.StatusBadRequest)
-			return
+			turnValid = false
+			reason = errorCode(err)
+			if errors.Is(err, wordlegameengine.ErrGameOver) {
+				status = wordlegameengine.StatusAlreadyFinished
+			}
+		} else {
+			game.PlayGuess(guess)
+			played = true
+			status = game.Status()
 		}
-		if err := firstGuess.Validate(); err != nil {
-			http.Error(w, fmt.Errorf("invalid past guess %q: %w", req.Turns[0].Guess, err).Error(), http.StatusBadRequest)
-			return
+	}
+
+	resp := MultiResponse{
+		GameStatus:       status.String(),
+		TurnValid:        turnValid,
+		InvalidReason:    reason,
+		GuessesRemaining: game.GuessesRemaining(),
+		Boards:           make([]BoardResponse, len(game.Boards)),
+	}
+	for i, board := range game.Boards {
+		resp.Boards[i] = BoardResponse{
+			Solved:             game.Solved(i),
+			ShortlistReduction: newShortlistReduction(before[i], board.ShortlistLength()),
+		}
+		if played && !wasSolved[i] {
+			resp.Boards[i].Feedback = board.LastFeedback().String()
+		}
+	}
+	return resp, nil
+}
+
+func assistHandler(w http.ResponseWriter, r *http.Request) {
+	var req AssistRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	game, apiErr := replayTurns(wordlegameengine.Solution{}, req.Turns, wordlegameengine.DefaultRules())
+	if apiErr != nil {
+		writeError(w, apiErr)
+		return
+	}
+
+	candidates := make([]string, 0, game.ShortlistLength())
+	for word := range game.SolutionShortlist.All() {
+		candidates = append(candidates, word.String())
+	}
+	resp := AssistResponse{
+		GameStatus: game.Status().String(),
+		Count:      len(candidates),
+		Candidates: candidates,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func suggestHandler(w http.ResponseWriter, r *http.Request) {
+	var req SuggestRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	strategyName := req.Strategy
+	if strategyName == "" {
+		strategyName = wordlesolver.EntropyStrategy.Name()
+	}
+	strategy, err := wordlesolver.StrategyByName(strategyName)
+	if err != nil {
+		writeError(w, newAPIError(err))
+		return
+	}
+
+	var sol wordlegameengine.Solution
+	if req.Solution != "" {
+		sol, err = wordlegameengine.NewSolution(req.Solution)
+		if err == nil {
+			err = sol.Validate()
 		}
-		firstFeedback, err := wordlegameengine.ParseFeedback(req.Turns[0].Feedback)
 		if err != nil {
-			http.Error(w, fmt.Errorf("invalid feedback %q: %w", req.Turns[0].Feedback, err).Error(), http.StatusBadRequest)
+			writeError(w, newAPIError(err))
 			return
 		}
-		cacheKey = wordlegameengine.MakeCacheKey(firstGuess, firstFeedback)
-		cachedShortlist, cached = wordlegameengine.FirstTurnCache.Get(cacheKey)
 	}
 
+	game, apiErr := replayTurns(sol, req.Turns, wordlegameengine.DefaultRules())
+	if apiErr != nil {
+		writeError(w, apiErr)
+		return
+	}
+
+	topN := req.TopN
+	if topN <= 0 {
+		topN = defaultTopN
+	}
+	scored := wordlesolver.Suggest(game, strategy, topN)
+
+	resp := SuggestResponse{
+		Strategy:       strategy.Name(),
+		ShortlistCount: game.ShortlistLength(),
+		Suggestions:    make([]Suggestion, len(scored)),
+	}
+	for i, s := range scored {
+		resp.Suggestions[i] = Suggestion{
+			Guess:       s.Guess.String(),
+			Score:       s.Score,
+			InShortlist: s.InShortlist,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// replayTurns creates a game for the solution (the zero Solution for assistant mode) under the
+// rules, and replays the past turns into it, starting from the longest cached prefix of the turns.
+// Each turn must be allowed by the rules, which must be valid
+func replayTurns(sol wordlegameengine.Solution, turns []Turn, rules wordlegameengine.Rules) (*wordlegameengine.Game, *apiError) {
+	// Validate past turns
+	guesses := make([]wordlegameengine.Word, len(turns))
+	feedbacks := make([]wordlegameengine.Feedback, len(turns))
+	for i, turn := range turns {
+		var apiErr *apiError
+		guesses[i], feedbacks[i], apiErr = parseTurn(i, turn)
+		if apiErr != nil {
+			return nil, apiErr
+		}
+	}
+
+	// Start from the longest prefix of the turns with a cached shortlist
+	cachedTurns, cachedShortlist := wordlegameengine.FirstTurnCache.LongestPrefix(guesses, feedbacks)
+
 	// Create game based on cache status
 	var game *wordlegameengine.Game
 
-	if haveFirstTurn && cached {
-		// Cache hit: Create game with cached shortlist
+	if cachedTurns > 0 {
+		// Cache hit: Create game with cached shortlist, and record the cached turns in its history
 		game = wordlegameengine.NewGameWithShortlist(sol, cachedShortlist)
+		game.Rules = rules
+		for i := 0; i < cachedTurns; i++ {
+			if err := game.RecordTurn(guesses[i], feedbacks[i]); err != nil {
+				return nil, newTurnError(i, err)
+			}
+		}
 	} else {
 		// Cache miss or no turns: Create game normally
 		game = wordlegameengine.NewGame(sol)
+		game.Rules = rules
 	}
 
-	// Replay turns (skip first turn if cache hit)
-	startIdx := 0
-	if haveFirstTurn && cached {
-		startIdx = 1 // Skip first turn - already in cached shortlist
+	// Replay the remaining turns, caching the shortlist after each one. Concurrent requests
+	// replaying the same turns share the computation. Past turns must not continue after the game
+	// was won or lost
+	for i := cachedTurns; i < len(turns); i++ {
+		if err := game.ReplayTurnCached(wordlegameengine.FirstTurnCache, guesses[i], feedbacks[i]); err != nil {
+			return nil, newTurnError(i, err)
+		}
 	}
 
-	for i := startIdx; i < len(req.Turns); i++ {
-		turn := req.Turns[i]
-		guess, err := wordlegameengine.NewWord(turn.Guess)
-		if err != nil {
-			http.Error(w, fmt.Errorf("invalid past guess %q: %w", turn.Guess, err).Error(), http.StatusBadRequest)
-			return
-		}
-		if err := guess.Validate(); err != nil {
-			http.Error(w, fmt.Errorf("invalid past guess %q: %w", turn.Guess, err).Error(), http.StatusBadRequest)
-			return
-		}
-		feedback, err := wordlegameengine.ParseFeedback(turn.Feedback)
-		if err != nil {
-			http.Error(w, fmt.Errorf("invalid feedback %q: %w", turn.Feedback, err).Error(), http.StatusBadRequest)
-			return
-		}
-		game.ReplayTurn(guess, feedback)
+	return game, nil
+}
+
+func cacheStatsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		writeError(w, &apiError{
+			Status: http.StatusMethodNotAllowed,
+			Detail: ErrorDetail{Code: CodeMethodNotAllowed, Message: "Method not allowed"},
+		})
+		return
 	}
 
-	// Cache on first-turn miss
-	if haveFirstTurn && !cached && len(req.Turns) == 1 {
-		shortlistCopy := make([]wordlegameengine.Word, len(game.SolutionShortlist))
-		copy(shortlistCopy, game.SolutionShortlist)
-		wordlegameengine.FirstTurnCache.Put(cacheKey, shortlistCopy)
+	stats := wordlegameengine.FirstTurnCache.Stats()
+	resp := CacheStatsResponse{
+		Hits:         stats.Hits,
+		Misses:       stats.Misses,
+		Deduplicated: stats.Deduplicated,
+		Evictions:    stats.Evictions,
+		Entries:      stats.Entries,
+		Bytes:        stats.Bytes,
+		MaxBytes:     stats.MaxBytes,
 	}
 
-	// Get shortlist length BEFORE playing proposed guess
-	before := game.ShortlistLength()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
 
-	// Calculate real feedback and shortlist reduction if proposed_guess is provided
-	feedbackStr := ""
-	after := before // If no proposed guess, after = before (no reduction)
-	if req.ProposedGuess != "" {
-		guess, _ := wordlegameengine.NewWord(req.ProposedGuess)
-		feedback := sol.CheckGuess(guess)
-		feedbackStr = feedback.String()
-		game.PlayGuess(guess)
-		after = game.ShortlistLength()
+// loadFeedbackTable loads the precomputed feedback table from path. If the file does not exist,
+// the table is built and saved there
+func loadFeedbackTable(path string) error {
+	table, err := wordlegameengine.LoadFeedbackTable(path)
+	if errors.Is(err, os.ErrNotExist) {
+		wordlegameengine.InitFeedbackTable()
+		return wordlegameengine.PrecomputedFeedback.Save(path)
 	}
+	if err != nil {
+		return err
+	}
+	return wordlegameengine.UseFeedbackTable(table)
+}
 
-	// Calculate ratio (handle division by zero)
-	ratio := 0.0
-	if before > 0 {
-		ratio = 1.0 - (float64(after) / float64(before))
+// restoreSnapshot loads a cache snapshot from path into the global cache. A missing or unusable
+// snapshot is logged and skipped, so the server still starts with an empty cache
+func restoreSnapshot(path string) {
+	err := wordlegameengine.FirstTurnCache.LoadFile(path)
+	switch {
+	case err == nil:
+		log.Printf("restored cache snapshot from %s, %d shortlists cached", path, wordlegameengine.FirstTurnCache.Stats().Entries)
+	case errors.Is(err, os.ErrNotExist):
+		log.Printf("no cache snapshot at %s, starting empty", path)
+	default:
+		log.Printf("ignoring cache snapshot %s: %v", path, err)
 	}
+}
 
-	resp := Response{
-		GameStatus: "ongoing",
-		TurnValid:  true,
-		ShortlistReduction: struct {
-			Before int     `json:"before"`
-			After  int     `json:"after"`
-			Ratio  float64 `json:"ratio"`
-		}{
-			Before: before,
-			After:  after,
-			Ratio:  ratio,
-		},
-		Feedback: feedbackStr,
+// saveSnapshot writes the global cache to path, logging any failure
+func saveSnapshot(path string) {
+	start := time.Now()
+	if err := wordlegameengine.FirstTurnCache.SaveFile(path); err != nil {
+		log.Printf("saving cache snapshot: %v", err)
+		return
 	}
+	log.Printf("saved cache snapshot to %s in %v", path, time.Since(start).Round(time.Millisecond))
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(resp)
+// snapshotPeriodically saves the global cache to path every interval until ctx is done
+func snapshotPeriodically(ctx context.Context, path string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			saveSnapshot(path)
+		}
+	}
 }
 
 func main() {
-	if err := wordlegameengine.LoadWordlists("./data"); err != nil {
+	dataDir := flag.String("data", "", "directory containing the wordlists, default the bundled lists")
+	feedbackTablePath := flag.String("feedback-table", "", "precomputed feedback table file, built and saved if missing")
+	warmPath := flag.String("warm", "", "first-turn shortlists file from wordle-precompute, loaded into the cache at startup")
+	cacheBytes := flag.Int64("cache-bytes", wordlegameengine.DefaultCacheBytes, "shortlist cache budget in bytes, 0 for unbounded")
+	snapshotPath := flag.String("snapshot", "", "cache snapshot file, restored at startup and saved periodically and on shutdown")
+	snapshotInterval := flag.Duration("snapshot-interval", 10*time.Minute, "time between cache snapshots, 0 to save only on shutdown")
+	flag.IntVar(&batchWorkers, "batch-workers", batchWorkers, "requests of an /api/evaluate/batch body evaluated in parallel")
+	flag.Parse()
+
+	report, err := wordlegameengine.LoadWordlistsDir(*dataDir)
+	if err != nil {
 		log.Fatal(err)
 	}
+	if !report.OK() {
+		log.Printf("wordlists: %s", report)
+	}
 
 	// Initialize the B-tree cache
-	wordlegameengine.InitCache()
+	wordlegameengine.InitCacheWithBudget(*cacheBytes)
+
+	// Warm the cache before accepting traffic. Snapshot entries are loaded last, as the most
+	// recently used
+	if *warmPath != "" {
+		n, err := wordlegameengine.LoadFirstTurns(*warmPath, wordlegameengine.FirstTurnCache)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("loaded %d first-turn shortlists from %s", n, *warmPath)
+	}
+	if *snapshotPath != "" {
+		restoreSnapshot(*snapshotPath)
+	}
+
+	if *feedbackTablePath != "" {
+		if err := loadFeedbackTable(*feedbackTablePath); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if *snapshotPath != "" && *snapshotInterval > 0 {
+		go snapshotPeriodically(ctx, *snapshotPath, *snapshotInterval)
+	}
 
 	http.HandleFunc("/api/evaluate", evaluateHandler)
-	http.ListenAndServe(":9111", nil)
+	http.HandleFunc("/api/evaluate/multi", evaluateMultiHandler)
+	http.HandleFunc("/api/evaluate/batch", evaluateBatchHandler)
+	http.HandleFunc("/api/assist", assistHandler)
+	http.HandleFunc("/api/suggest", suggestHandler)
+	http.HandleFunc("/api/cache/stats", cacheStatsHandler)
+
+	server := &http.Server{Addr: ":9111"}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+
+	// Wait for a shutdown signal, finish in-flight requests, then take a final snapshot
+	<-ctx.Done()
+	log.Print("shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("shutdown: %v", err)
+	}
+	if *snapshotPath != "" {
+		saveSnapshot(*snapshotPath)
+	}
 }
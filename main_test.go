@@ -7,7 +7,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/sam-bee/wordle-game-engine/pkg/wordlegameengine"
@@ -52,17 +55,17 @@ func TestEvaluateHandler(t *testing.T) {
 		{
 			name:       "invalid proposed guess length",
 			reqBody:    `{"solution":"aback","turns":[],"proposed_guess":"abc"}`,
-			wantStatus: http.StatusBadRequest,
+			wantStatus: http.StatusOK,
 		},
 		{
 			name:       "proposed guess invalid chars",
 			reqBody:    `{"solution":"aback","turns":[],"proposed_guess":"ABCDE"}`,
-			wantStatus: http.StatusBadRequest,
+			wantStatus: http.StatusOK,
 		},
 		{
 			name:       "proposed guess not in allowed guesses",
 			reqBody:    `{"solution":"aback","turns":[],"proposed_guess":"abcde"}`,
-			wantStatus: http.StatusBadRequest,
+			wantStatus: http.StatusOK,
 		},
 		{
 			name:       "invalid past turn guess",
@@ -76,9 +79,14 @@ func TestEvaluateHandler(t *testing.T) {
 		},
 		{
 			name:       "valid past turn",
-			reqBody:    `{"solution":"aback","turns":[{"guess":"aahed","feedback":"-----"}],"proposed_guess":""}`,
+			reqBody:    `{"solution":"aback","turns":[{"guess":"aahed","feedback":"GY---"}],"proposed_guess":""}`,
 			wantStatus: http.StatusOK,
 		},
+		{
+			name:       "past turn feedback does not match solution",
+			reqBody:    `{"solution":"aback","turns":[{"guess":"aahed","feedback":"-----"}],"proposed_guess":""}`,
+			wantStatus: http.StatusBadRequest,
+		},
 		{
 			name:       "invalid feedback character",
 			reqBody:    `{"solution":"aback","turns":[{"guess":"aahed","feedback":"-G-!-"}],"proposed_guess":""}`,
@@ -231,7 +239,7 @@ func TestEvaluateHandler_ShortlistReduction_EmptyProposedGuess(t *testing.T) {
 	}
 }
 
-func TestEvaluateHandler_Cache_FirstTurnCacheMiss(t *testing.T) {
+func TestEvaluateHandler_Cache_TurnCacheMiss(t *testing.T) {
 	// Initialize cache for this test
 	wordlegameengine.InitCache()
 
@@ -263,12 +271,12 @@ func TestEvaluateHandler_Cache_FirstTurnCacheMiss(t *testing.T) {
 	if !found {
 		t.Error("Expected result to be cached after first request")
 	}
-	if len(cachedShortlist) != resp1.ShortlistReduction.Before {
-		t.Errorf("Cached shortlist length = %d, want %d", len(cachedShortlist), resp1.ShortlistReduction.Before)
+	if cachedShortlist.Count() != resp1.ShortlistReduction.Before {
+		t.Errorf("Cached shortlist length = %d, want %d", cachedShortlist.Count(), resp1.ShortlistReduction.Before)
 	}
 }
 
-func TestEvaluateHandler_Cache_FirstTurnCacheHit(t *testing.T) {
+func TestEvaluateHandler_Cache_TurnCacheHit(t *testing.T) {
 	// Initialize cache for this test
 	wordlegameengine.InitCache()
 
@@ -365,7 +373,7 @@ func TestEvaluateHandler_Cache_DifferentFirstTurns(t *testing.T) {
 
 	// Two different first turns should produce different cache entries
 	reqBody1 := `{"solution":"apple","turns":[{"guess":"raise","feedback":"-Y--G"}],"proposed_guess":""}`
-	reqBody2 := `{"solution":"apple","turns":[{"guess":"crane","feedback":"GGGGG"}],"proposed_guess":""}`
+	reqBody2 := `{"solution":"apple","turns":[{"guess":"crane","feedback":"--Y-G"}],"proposed_guess":""}`
 
 	// First request
 	req1 := httptest.NewRequest(http.MethodPost, "/api/evaluate", strings.NewReader(reqBody1))
@@ -390,9 +398,7 @@ func TestEvaluateHandler_Cache_DifferentFirstTurns(t *testing.T) {
 		t.Fatalf("Second request: failed to decode response: %v", err)
 	}
 
-	// For "crane" with "GGGGG", the shortlist should be reduced to 1 (only "apple"... wait, solution is apple)
-	// Actually if solution is "apple" and guess is "crane" with "GGGGG", that's impossible
-	// But the shortlist should still be valid
+	// "crane" against "apple" gives "--Y-G", which leaves "apple" on the shortlist
 	if resp2.ShortlistReduction.Before <= 0 {
 		t.Errorf("Second request: Before = %d, should be > 0", resp2.ShortlistReduction.Before)
 	}
@@ -403,7 +409,7 @@ func TestEvaluateHandler_Cache_DifferentFirstTurns(t *testing.T) {
 	key1 := wordlegameengine.MakeCacheKey(guess1, feedback1)
 
 	guess2, _ := wordlegameengine.NewWord("crane")
-	feedback2, _ := wordlegameengine.ParseFeedback("GGGGG")
+	feedback2, _ := wordlegameengine.ParseFeedback("--Y-G")
 	key2 := wordlegameengine.MakeCacheKey(guess2, feedback2)
 
 	_, found1 := wordlegameengine.FirstTurnCache.Get(key1)
@@ -416,3 +422,1048 @@ func TestEvaluateHandler_Cache_DifferentFirstTurns(t *testing.T) {
 		t.Error("Second cache entry should exist")
 	}
 }
+
+func TestEvaluateHandler_Cache_TurnPrefixes(t *testing.T) {
+	wordlegameengine.InitCache()
+
+	guesses := []wordlegameengine.Word{mustNewWord("raise"), mustNewWord("mount")}
+	feedbacks := make([]wordlegameengine.Feedback, 2)
+	feedbacks[0], _ = wordlegameengine.ParseFeedback("----Y")
+	feedbacks[1], _ = wordlegameengine.ParseFeedback("-----")
+	reqBody := `{"solution":"wheel","turns":[{"guess":"raise","feedback":"----Y"},{"guess":"mount","feedback":"-----"}],"proposed_guess":""}`
+
+	// A miss caches the shortlist after every prefix of the turns
+	req := httptest.NewRequest(http.MethodPost, "/api/evaluate", strings.NewReader(reqBody))
+	w := httptest.NewRecorder()
+	evaluateHandler(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("First request failed: %v (body %q)", w.Code, w.Body.String())
+	}
+	var resp Response
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	for n := 1; n <= len(guesses); n++ {
+		key := wordlegameengine.MakeTurnsKey(guesses[:n], feedbacks[:n])
+		shortlist, found := wordlegameengine.FirstTurnCache.Get(key)
+		if !found {
+			t.Errorf("Expected %q to be cached", key)
+		}
+		if n == len(guesses) && shortlist.Count() != resp.ShortlistReduction.Before {
+			t.Errorf("Cached shortlist length = %d, want %d", shortlist.Count(), resp.ShortlistReduction.Before)
+		}
+	}
+
+	// A hit starts from the longest cached prefix. Replace its shortlist with one the turns
+	// could not produce, to show the turns were not replayed
+	key := wordlegameengine.MakeTurnsKey(guesses, feedbacks)
+	wordlegameengine.FirstTurnCache.Put(key, wordlegameengine.NewShortlist([]wordlegameengine.Word{mustNewWord("apple"), mustNewWord("wheel")}))
+
+	req = httptest.NewRequest(http.MethodPost, "/api/evaluate", strings.NewReader(reqBody))
+	w = httptest.NewRecorder()
+	evaluateHandler(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Second request failed: %v (body %q)", w.Code, w.Body.String())
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.ShortlistReduction.Before != 2 {
+		t.Errorf("Second request: Before = %d, want 2 (cached shortlist used)", resp.ShortlistReduction.Before)
+	}
+}
+
+func mustNewWord(s string) wordlegameengine.Word {
+	w, err := wordlegameengine.NewWord(s)
+	if err != nil {
+		panic(err)
+	}
+	return w
+}
+
+func TestEvaluateHandler_GameStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		reqBody    string
+		wantCode   int
+		wantStatus string
+	}{
+		{
+			name:       "no turns, no proposed guess",
+			reqBody:    `{"solution":"crane","turns":[],"proposed_guess":""}`,
+			wantCode:   http.StatusOK,
+			wantStatus: "ongoing",
+		},
+		{
+			name:       "proposed guess wins",
+			reqBody:    `{"solution":"crane","turns":[{"guess":"slate","feedback":"--G-G"}],"proposed_guess":"crane"}`,
+			wantCode:   http.StatusOK,
+			wantStatus: "won",
+		},
+		{
+			name:       "proposed guess does not win",
+			reqBody:    `{"solution":"crane","turns":[],"proposed_guess":"slate"}`,
+			wantCode:   http.StatusOK,
+			wantStatus: "ongoing",
+		},
+		{
+			name:       "replayed turns already won",
+			reqBody:    `{"solution":"crane","turns":[{"guess":"crane","feedback":"GGGGG"}],"proposed_guess":""}`,
+			wantCode:   http.StatusOK,
+			wantStatus: "won",
+		},
+		{
+			name: "sixth guess misses",
+			reqBody: `{"solution":"crane","turns":[{"guess":"slate","feedback":"--G-G"},{"guess":"trace","feedback":"-GGYG"},` +
+				`{"guess":"brace","feedback":"-GGYG"},{"guess":"grace","feedback":"-GGYG"},{"guess":"space","feedback":"--GYG"}],"proposed_guess":"place"}`,
+			wantCode:   http.StatusOK,
+			wantStatus: "lost",
+		},
+		{
+			name:       "proposed guess after win",
+			reqBody:    `{"solution":"crane","turns":[{"guess":"crane","feedback":"GGGGG"}],"proposed_guess":"slate"}`,
+			wantCode:   http.StatusOK,
+			wantStatus: "already_finished",
+		},
+		{
+			name:     "past turns continue after win",
+			reqBody:  `{"solution":"crane","turns":[{"guess":"crane","feedback":"GGGGG"},{"guess":"slate","feedback":"--G-G"}],"proposed_guess":""}`,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wordlegameengine.InitCache()
+			req := httptest.NewRequest(http.MethodPost, "/api/evaluate", strings.NewReader(tt.reqBody))
+			w := httptest.NewRecorder()
+			evaluateHandler(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("handler returned wrong status code: got %v want %v (body %q)", w.Code, tt.wantCode, w.Body.String())
+			}
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			var resp Response
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.GameStatus != tt.wantStatus {
+				t.Errorf("GameStatus = %q, want %q", resp.GameStatus, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestEvaluateHandler_InvalidProposedGuess(t *testing.T) {
+	tests := []struct {
+		name       string
+		reqBody    string
+		wantReason string
+	}{
+		{
+			name:       "wrong length",
+			reqBody:    `{"solution":"crane","turns":[],"proposed_guess":"abc"}`,
+			wantReason: ReasonWrongLength,
+		},
+		{
+			name:       "bad characters",
+			reqBody:    `{"solution":"crane","turns":[],"proposed_guess":"ABCDE"}`,
+			wantReason: ReasonBadCharacters,
+		},
+		{
+			name:       "not in wordlist",
+			reqBody:    `{"solution":"crane","turns":[],"proposed_guess":"abcde"}`,
+			wantReason: ReasonNotInWordlist,
+		},
+		{
+			name:       "repeated guess",
+			reqBody:    `{"solution":"crane","turns":[{"guess":"slate","feedback":"--G-G"}],"proposed_guess":"slate"}`,
+			wantReason: ReasonRepeatedGuess,
+		},
+		{
+			name:       "game already over",
+			reqBody:    `{"solution":"crane","turns":[{"guess":"crane","feedback":"GGGGG"}],"proposed_guess":"slate"}`,
+			wantReason: ReasonGameOver,
+		},
+		{
+			name:       "hard mode violation",
+			reqBody:    `{"solution":"crane","turns":[{"guess":"slate","feedback":"--G-G"}],"proposed_guess":"crony","hard_mode":true}`,
+			wantReason: ReasonHardMode,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wordlegameengine.InitCache()
+			req := httptest.NewRequest(http.MethodPost, "/api/evaluate", strings.NewReader(tt.reqBody))
+			w := httptest.NewRecorder()
+			evaluateHandler(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("handler returned wrong status code: got %v want %v (body %q)", w.Code, http.StatusOK, w.Body.String())
+			}
+
+			var resp Response
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.TurnValid {
+				t.Error("TurnValid = true, want false")
+			}
+			if resp.InvalidReason != tt.wantReason {
+				t.Errorf("InvalidReason = %q, want %q", resp.InvalidReason, tt.wantReason)
+			}
+			if resp.ShortlistReduction.After != resp.ShortlistReduction.Before {
+				t.Errorf("invalid turn changed the shortlist: before %d, after %d",
+					resp.ShortlistReduction.Before, resp.ShortlistReduction.After)
+			}
+			if resp.Feedback != "" {
+				t.Errorf("Feedback = %q, want empty for an invalid turn", resp.Feedback)
+			}
+		})
+	}
+}
+
+func TestEvaluateHandler_ValidProposedGuess_NoInvalidReason(t *testing.T) {
+	reqBody := `{"solution":"crane","turns":[],"proposed_guess":"slate"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/evaluate", strings.NewReader(reqBody))
+	w := httptest.NewRecorder()
+	evaluateHandler(w, req)
+
+	var resp Response
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !resp.TurnValid {
+		t.Error("TurnValid = false, want true")
+	}
+	if resp.InvalidReason != "" {
+		t.Errorf("InvalidReason = %q, want empty", resp.InvalidReason)
+	}
+}
+
+func TestEvaluateHandler_ErrorEnvelope(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		reqBody   string
+		wantCode  int
+		wantError ErrorDetail
+	}{
+		{
+			name:      "method not allowed",
+			method:    http.MethodGet,
+			reqBody:   ``,
+			wantCode:  http.StatusMethodNotAllowed,
+			wantError: ErrorDetail{Code: CodeMethodNotAllowed},
+		},
+		{
+			name:      "invalid json",
+			method:    http.MethodPost,
+			reqBody:   `{"solution":`,
+			wantCode:  http.StatusBadRequest,
+			wantError: ErrorDetail{Code: CodeInvalidJSON},
+		},
+		{
+			name:      "solution not in allowed solutions",
+			method:    http.MethodPost,
+			reqBody:   `{"solution":"aahed","turns":[],"proposed_guess":""}`,
+			wantCode:  http.StatusBadRequest,
+			wantError: ErrorDetail{Code: ReasonNotInWordlist, Field: "solution"},
+		},
+		{
+			name:      "bad guess in second turn",
+			method:    http.MethodPost,
+			reqBody:   `{"solution":"crane","turns":[{"guess":"slate","feedback":"--G-G"},{"guess":"abcde","feedback":"-----"}],"proposed_guess":""}`,
+			wantCode:  http.StatusBadRequest,
+			wantError: ErrorDetail{Code: ReasonNotInWordlist, Field: "guess", Turn: intPtr(1)},
+		},
+		{
+			name:      "bad feedback in first turn",
+			method:    http.MethodPost,
+			reqBody:   `{"solution":"crane","turns":[{"guess":"slate","feedback":"--G!G"}],"proposed_guess":""}`,
+			wantCode:  http.StatusBadRequest,
+			wantError: ErrorDetail{Code: ReasonBadCharacters, Field: "feedback", Turn: intPtr(0)},
+		},
+		{
+			name:      "turn after game won",
+			method:    http.MethodPost,
+			reqBody:   `{"solution":"crane","turns":[{"guess":"crane","feedback":"GGGGG"},{"guess":"slate","feedback":"--G-G"}],"proposed_guess":""}`,
+			wantCode:  http.StatusBadRequest,
+			wantError: ErrorDetail{Code: ReasonGameOver, Turn: intPtr(1)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/evaluate", strings.NewReader(tt.reqBody))
+			w := httptest.NewRecorder()
+			evaluateHandler(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("handler returned wrong status code: got %v want %v", w.Code, tt.wantCode)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", ct)
+			}
+
+			var resp ErrorResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode error response: %v", err)
+			}
+			if resp.Error.Code != tt.wantError.Code {
+				t.Errorf("Error.Code = %q, want %q", resp.Error.Code, tt.wantError.Code)
+			}
+			if resp.Error.Message == "" {
+				t.Error("Error.Message is empty")
+			}
+			if resp.Error.Field != tt.wantError.Field {
+				t.Errorf("Error.Field = %q, want %q", resp.Error.Field, tt.wantError.Field)
+			}
+			switch {
+			case tt.wantError.Turn == nil && resp.Error.Turn != nil:
+				t.Errorf("Error.Turn = %d, want none", *resp.Error.Turn)
+			case tt.wantError.Turn != nil && resp.Error.Turn == nil:
+				t.Errorf("Error.Turn missing, want %d", *tt.wantError.Turn)
+			case tt.wantError.Turn != nil && *resp.Error.Turn != *tt.wantError.Turn:
+				t.Errorf("Error.Turn = %d, want %d", *resp.Error.Turn, *tt.wantError.Turn)
+			}
+		})
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestEvaluateHandler_HardModeOff(t *testing.T) {
+	// The same turns as the hard mode cases are allowed when hard_mode is not set
+	reqBody := `{"solution":"crane","turns":[{"guess":"slate","feedback":"--G-G"},{"guess":"crony","feedback":"GG-G-"}],"proposed_guess":"moist"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/evaluate", strings.NewReader(reqBody))
+	w := httptest.NewRecorder()
+	evaluateHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v (body %q)", w.Code, http.StatusOK, w.Body.String())
+	}
+	var resp Response
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !resp.TurnValid {
+		t.Errorf("TurnValid = false (%s), want true outside hard mode", resp.InvalidReason)
+	}
+}
+
+func TestEvaluateHandler_InconsistentTurns(t *testing.T) {
+	tests := []struct {
+		name     string
+		reqBody  string
+		wantCode string
+		wantTurn int
+	}{
+		{
+			name:     "first turn does not match solution",
+			reqBody:  `{"solution":"slate","turns":[{"guess":"crane","feedback":"GGGGG"}],"proposed_guess":""}`,
+			wantCode: CodeFeedbackMismatch,
+			wantTurn: 0,
+		},
+		{
+			name:     "second turn does not match solution",
+			reqBody:  `{"solution":"crane","turns":[{"guess":"slate","feedback":"--G-G"},{"guess":"trace","feedback":"-----"}],"proposed_guess":""}`,
+			wantCode: CodeFeedbackMismatch,
+			wantTurn: 1,
+		},
+		{
+			name:     "second turn ignores hints in hard mode",
+			reqBody:  `{"solution":"crane","turns":[{"guess":"slate","feedback":"--G-G"},{"guess":"crony","feedback":"GG-G-"}],"proposed_guess":"","hard_mode":true}`,
+			wantCode: ReasonHardMode,
+			wantTurn: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Run twice, so the second request takes the cache hit path
+			wordlegameengine.InitCache()
+			for run := 0; run < 2; run++ {
+				req := httptest.NewRequest(http.MethodPost, "/api/evaluate", strings.NewReader(tt.reqBody))
+				w := httptest.NewRecorder()
+				evaluateHandler(w, req)
+
+				if w.Code != http.StatusBadRequest {
+					t.Fatalf("run %d: handler returned wrong status code: got %v want %v", run, w.Code, http.StatusBadRequest)
+				}
+				var resp ErrorResponse
+				if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+					t.Fatalf("run %d: failed to decode error response: %v", run, err)
+				}
+				if resp.Error.Code != tt.wantCode {
+					t.Errorf("run %d: Error.Code = %q, want %q", run, resp.Error.Code, tt.wantCode)
+				}
+				if resp.Error.Turn == nil || *resp.Error.Turn != tt.wantTurn {
+					t.Errorf("run %d: Error.Turn = %v, want %d", run, resp.Error.Turn, tt.wantTurn)
+				}
+			}
+		})
+	}
+}
+
+func TestEvaluateHandler_Rules(t *testing.T) {
+	tests := []struct {
+		name       string
+		reqBody    string
+		wantValid  bool
+		wantReason string
+		wantStatus string
+	}{
+		{
+			name:       "guesses used up",
+			reqBody:    `{"solution":"crane","turns":[{"guess":"moist","feedback":"-----"},{"guess":"pudgy","feedback":"-----"}],"proposed_guess":"crane","rules":{"max_guesses":2}}`,
+			wantReason: ReasonGameOver,
+			wantStatus: "already_finished",
+		},
+		{
+			name:       "guesses left",
+			reqBody:    `{"solution":"crane","turns":[{"guess":"moist","feedback":"-----"}],"proposed_guess":"crane","rules":{"max_guesses":2}}`,
+			wantValid:  true,
+			wantStatus: "won",
+		},
+		{
+			name:       "guess not a solution",
+			reqBody:    `{"solution":"crane","turns":[],"proposed_guess":"xylyl","rules":{"guess_list":"solutions"}}`,
+			wantReason: ReasonNotInWordlist,
+			wantStatus: "ongoing",
+		},
+		{
+			name:       "repeats allowed",
+			reqBody:    `{"solution":"crane","turns":[{"guess":"slate","feedback":"--G-G"}],"proposed_guess":"slate","rules":{"allow_repeats":true}}`,
+			wantValid:  true,
+			wantStatus: "ongoing",
+		},
+		{
+			name:       "hard mode",
+			reqBody:    `{"solution":"crane","turns":[{"guess":"slate","feedback":"--G-G"}],"proposed_guess":"crony","rules":{"hard_mode":true}}`,
+			wantReason: ReasonHardMode,
+			wantStatus: "ongoing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wordlegameengine.InitCache()
+			req := httptest.NewRequest(http.MethodPost, "/api/evaluate", strings.NewReader(tt.reqBody))
+			w := httptest.NewRecorder()
+			evaluateHandler(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("handler returned wrong status code: got %v want %v (body %q)", w.Code, http.StatusOK, w.Body.String())
+			}
+			var resp Response
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.TurnValid != tt.wantValid || resp.InvalidReason != tt.wantReason {
+				t.Errorf("TurnValid = %v (%q), want %v (%q)", resp.TurnValid, resp.InvalidReason, tt.wantValid, tt.wantReason)
+			}
+			if resp.GameStatus != tt.wantStatus {
+				t.Errorf("GameStatus = %q, want %q", resp.GameStatus, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestEvaluateHandler_InvalidRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		reqBody  string
+		wantCode string
+	}{
+		{
+			name:     "unknown guess list",
+			reqBody:  `{"solution":"crane","turns":[],"proposed_guess":"","rules":{"guess_list":"answers"}}`,
+			wantCode: CodeInvalidRules,
+		},
+		{
+			name:     "negative max guesses",
+			reqBody:  `{"solution":"crane","turns":[],"proposed_guess":"","rules":{"max_guesses":-2}}`,
+			wantCode: CodeInvalidMaxGuesses,
+		},
+		{
+			name:     "turn past the limit",
+			reqBody:  `{"solution":"crane","turns":[{"guess":"moist","feedback":"-----"},{"guess":"pudgy","feedback":"-----"}],"proposed_guess":"","rules":{"max_guesses":1}}`,
+			wantCode: ReasonGameOver,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/evaluate", strings.NewReader(tt.reqBody))
+			w := httptest.NewRecorder()
+			evaluateHandler(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("handler returned wrong status code: got %v want %v", w.Code, http.StatusBadRequest)
+			}
+			var resp ErrorResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode error response: %v", err)
+			}
+			if resp.Error.Code != tt.wantCode {
+				t.Errorf("Error.Code = %q, want %q", resp.Error.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestAssistHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		reqBody        string
+		wantCode       int
+		wantStatus     string
+		wantCandidates []string
+	}{
+		{
+			name:       "no turns",
+			reqBody:    `{"turns":[]}`,
+			wantCode:   http.StatusOK,
+			wantStatus: "ongoing",
+		},
+		{
+			name:           "narrowed to one candidate",
+			reqBody:        `{"turns":[{"guess":"raise","feedback":"-Y--G"},{"guess":"ample","feedback":"G-GGG"}]}`,
+			wantCode:       http.StatusOK,
+			wantStatus:     "ongoing",
+			wantCandidates: []string{"apple"},
+		},
+		{
+			name:           "won",
+			reqBody:        `{"turns":[{"guess":"crane","feedback":"GGGGG"}]}`,
+			wantCode:       http.StatusOK,
+			wantStatus:     "won",
+			wantCandidates: []string{"crane"},
+		},
+		{
+			name:     "contradictory turns",
+			reqBody:  `{"turns":[{"guess":"slate","feedback":"-----"},{"guess":"least","feedback":"GGGGG"}]}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid guess",
+			reqBody:  `{"turns":[{"guess":"abcde","feedback":"-----"}]}`,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wordlegameengine.InitCache()
+			req := httptest.NewRequest(http.MethodPost, "/api/assist", strings.NewReader(tt.reqBody))
+			w := httptest.NewRecorder()
+			assistHandler(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("handler returned wrong status code: got %v want %v (body %q)", w.Code, tt.wantCode, w.Body.String())
+			}
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			var resp AssistResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.GameStatus != tt.wantStatus {
+				t.Errorf("GameStatus = %q, want %q", resp.GameStatus, tt.wantStatus)
+			}
+			if resp.Count != len(resp.Candidates) {
+				t.Errorf("Count = %d, but %d candidates returned", resp.Count, len(resp.Candidates))
+			}
+			if tt.wantCandidates == nil {
+				return
+			}
+			if strings.Join(resp.Candidates, ",") != strings.Join(tt.wantCandidates, ",") {
+				t.Errorf("Candidates = %v, want %v", resp.Candidates, tt.wantCandidates)
+			}
+		})
+	}
+}
+
+func TestAssistHandler_CandidatesSorted(t *testing.T) {
+	wordlegameengine.InitCache()
+	reqBody := `{"turns":[{"guess":"raise","feedback":"----Y"},{"guess":"mount","feedback":"-----"}]}`
+
+	// The first request misses the first-turn cache, the second hits it; both must return the
+	// candidates in the same (alphabetical) order
+	var responses [2]AssistResponse
+	for i := range responses {
+		req := httptest.NewRequest(http.MethodPost, "/api/assist", strings.NewReader(reqBody))
+		w := httptest.NewRecorder()
+		assistHandler(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("handler returned wrong status code: got %v want %v (body %q)", w.Code, http.StatusOK, w.Body.String())
+		}
+		if err := json.NewDecoder(w.Body).Decode(&responses[i]); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+	}
+
+	miss, hit := responses[0].Candidates, responses[1].Candidates
+	if len(miss) < 2 {
+		t.Fatalf("expected several candidates, got %v", miss)
+	}
+	if !slices.IsSorted(miss) {
+		t.Errorf("Candidates = %v, want alphabetical order", miss)
+	}
+	if !slices.Equal(miss, hit) {
+		t.Errorf("Candidates on cache hit = %v, want %v", hit, miss)
+	}
+}
+
+func TestSuggestHandler(t *testing.T) {
+	tests := []struct {
+		name      string
+		reqBody   string
+		wantCode  int
+		wantCount int
+		wantN     int
+		wantBest  string
+	}{
+		{
+			name:      "assistant mode, one candidate left",
+			reqBody:   `{"turns":[{"guess":"raise","feedback":"-Y--G"},{"guess":"ample","feedback":"G-GGG"}],"top_n":3}`,
+			wantCode:  http.StatusOK,
+			wantCount: 1,
+			wantN:     3,
+			wantBest:  "apple",
+		},
+		{
+			name:      "with solution, default top_n",
+			reqBody:   `{"solution":"apple","turns":[{"guess":"raise","feedback":"-Y--G"},{"guess":"ample","feedback":"G-GGG"}]}`,
+			wantCode:  http.StatusOK,
+			wantCount: 1,
+			wantN:     defaultTopN,
+			wantBest:  "apple",
+		},
+		{
+			name:     "invalid solution",
+			reqBody:  `{"solution":"aahed","turns":[]}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "feedback mismatch with solution",
+			reqBody:  `{"solution":"apple","turns":[{"guess":"raise","feedback":"-----"}]}`,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wordlegameengine.InitCache()
+			req := httptest.NewRequest(http.MethodPost, "/api/suggest", strings.NewReader(tt.reqBody))
+			w := httptest.NewRecorder()
+			suggestHandler(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("handler returned wrong status code: got %v want %v (body %q)", w.Code, tt.wantCode, w.Body.String())
+			}
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			var resp SuggestResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.ShortlistCount != tt.wantCount {
+				t.Errorf("ShortlistCount = %d, want %d", resp.ShortlistCount, tt.wantCount)
+			}
+			if len(resp.Suggestions) != tt.wantN {
+				t.Fatalf("got %d suggestions, want %d", len(resp.Suggestions), tt.wantN)
+			}
+			if best := resp.Suggestions[0]; best.Guess != tt.wantBest || !best.InShortlist {
+				t.Errorf("best suggestion = %+v, want %q on the shortlist", best, tt.wantBest)
+			}
+		})
+	}
+}
+
+func TestSuggestHandler_Strategy(t *testing.T) {
+	tests := []struct {
+		name         string
+		reqBody      string
+		wantCode     int
+		wantStrategy string
+	}{
+		{
+			name:         "default strategy",
+			reqBody:      `{"turns":[{"guess":"raise","feedback":"-Y--G"},{"guess":"ample","feedback":"G-GGG"}]}`,
+			wantCode:     http.StatusOK,
+			wantStrategy: "entropy",
+		},
+		{
+			name:         "minimax",
+			reqBody:      `{"turns":[{"guess":"raise","feedback":"-Y--G"},{"guess":"ample","feedback":"G-GGG"}],"strategy":"minimax"}`,
+			wantCode:     http.StatusOK,
+			wantStrategy: "minimax",
+		},
+		{
+			name:         "hard mode variant",
+			reqBody:      `{"turns":[{"guess":"raise","feedback":"-Y--G"},{"guess":"ample","feedback":"G-GGG"}],"strategy":"hard-expected-size"}`,
+			wantCode:     http.StatusOK,
+			wantStrategy: "hard-expected-size",
+		},
+		{
+			name:     "unknown strategy",
+			reqBody:  `{"turns":[],"strategy":"random"}`,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/suggest", strings.NewReader(tt.reqBody))
+			w := httptest.NewRecorder()
+			suggestHandler(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("handler returned wrong status code: got %v want %v (body %q)", w.Code, tt.wantCode, w.Body.String())
+			}
+			if tt.wantCode != http.StatusOK {
+				var resp ErrorResponse
+				if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+					t.Fatalf("failed to decode error response: %v", err)
+				}
+				if resp.Error.Code != CodeUnknownStrategy {
+					t.Errorf("Error.Code = %q, want %q", resp.Error.Code, CodeUnknownStrategy)
+				}
+				return
+			}
+
+			var resp SuggestResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.Strategy != tt.wantStrategy {
+				t.Errorf("Strategy = %q, want %q", resp.Strategy, tt.wantStrategy)
+			}
+			if len(resp.Suggestions) == 0 || resp.Suggestions[0].Guess != "apple" {
+				t.Errorf("Suggestions = %+v, want apple first", resp.Suggestions)
+			}
+		})
+	}
+}
+
+func TestCacheStatsHandler(t *testing.T) {
+	wordlegameengine.InitCacheWithBudget(1 << 20)
+
+	// One miss that populates the cache, then one hit
+	reqBody := `{"solution":"apple","turns":[{"guess":"raise","feedback":"-Y--G"}],"proposed_guess":""}`
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/api/evaluate", strings.NewReader(reqBody))
+		evaluateHandler(httptest.NewRecorder(), req)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/cache/stats", nil)
+	w := httptest.NewRecorder()
+	cacheStatsHandler(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", w.Code, http.StatusOK)
+	}
+
+	var resp CacheStatsResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Hits != 1 || resp.Misses != 1 || resp.Entries != 1 {
+		t.Errorf("hits = %d, misses = %d, entries = %d, want 1, 1, 1", resp.Hits, resp.Misses, resp.Entries)
+	}
+	if resp.Bytes <= 0 || resp.MaxBytes != 1<<20 {
+		t.Errorf("bytes = %d of %d, want > 0 of %d", resp.Bytes, resp.MaxBytes, 1<<20)
+	}
+
+	// Only GET is allowed
+	req = httptest.NewRequest(http.MethodPost, "/api/cache/stats", nil)
+	w = httptest.NewRecorder()
+	cacheStatsHandler(w, req)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST returned status %v, want %v", w.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestEvaluateHandler_ConcurrentMissesComputedOnce(t *testing.T) {
+	const numRequests = 32
+	wordlegameengine.InitCache()
+
+	reqBody := `{"solution":"apple","turns":[{"guess":"raise","feedback":"-Y--G"}],"proposed_guess":""}`
+	var wg sync.WaitGroup
+	codes := make([]int, numRequests)
+	for i := 0; i < numRequests; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			req := httptest.NewRequest(http.MethodPost, "/api/evaluate", strings.NewReader(reqBody))
+			w := httptest.NewRecorder()
+			evaluateHandler(w, req)
+			codes[i] = w.Code
+		}(i)
+	}
+	wg.Wait()
+
+	for i, code := range codes {
+		if code != http.StatusOK {
+			t.Errorf("request %d returned status %v, want %v", i, code, http.StatusOK)
+		}
+	}
+
+	// Each request is a hit, the one miss, or deduplicated against it
+	stats := wordlegameengine.FirstTurnCache.Stats()
+	if stats.Misses != 1 {
+		t.Errorf("misses = %d, want 1", stats.Misses)
+	}
+	if total := stats.Hits + stats.Misses + stats.Deduplicated; total != numRequests {
+		t.Errorf("hits + misses + deduplicated = %d, want %d", total, numRequests)
+	}
+}
+
+func TestSnapshot_SaveAndRestore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.bin")
+	wordlegameengine.InitCache()
+
+	reqBody := `{"turns":[{"guess":"raise","feedback":"----Y"},{"guess":"mount","feedback":"-----"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/assist", strings.NewReader(reqBody))
+	assistHandler(httptest.NewRecorder(), req)
+	saveSnapshot(path)
+
+	// A restart restores both turn prefixes
+	wordlegameengine.InitCache()
+	restoreSnapshot(path)
+	if entries := wordlegameengine.FirstTurnCache.Stats().Entries; entries != 2 {
+		t.Errorf("restored %d entries, want 2", entries)
+	}
+
+	// A missing snapshot leaves the cache empty
+	wordlegameengine.InitCache()
+	restoreSnapshot(filepath.Join(t.TempDir(), "missing.bin"))
+	if entries := wordlegameengine.FirstTurnCache.Stats().Entries; entries != 0 {
+		t.Errorf("cache has %d entries after a missing snapshot, want 0", entries)
+	}
+}
+
+func TestEvaluateMultiHandler(t *testing.T) {
+	reqBody := `{"solutions":["crane","slate"],"guesses":["crane"],"proposed_guess":"stale"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/evaluate/multi", strings.NewReader(reqBody))
+	w := httptest.NewRecorder()
+	evaluateMultiHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v (body %q)", w.Code, http.StatusOK, w.Body.String())
+	}
+	var resp MultiResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if resp.GameStatus != "ongoing" || !resp.TurnValid || resp.GuessesRemaining != 5 {
+		t.Errorf("status %q, turn valid %v, %d guesses left, want ongoing, true, 5", resp.GameStatus, resp.TurnValid, resp.GuessesRemaining)
+	}
+	if len(resp.Boards) != 2 {
+		t.Fatalf("got %d boards, want 2", len(resp.Boards))
+	}
+
+	// Board 0 was solved by the past guess, so takes no part in the proposed guess
+	solved := resp.Boards[0]
+	if !solved.Solved || solved.Feedback != "" || solved.ShortlistReduction.Before != solved.ShortlistReduction.After {
+		t.Errorf("board 0 = %+v, want solved with no feedback or reduction", solved)
+	}
+	board := resp.Boards[1]
+	if board.Solved || board.Feedback != "GYGYG" {
+		t.Errorf("board 1 solved = %v, feedback = %q, want false, GYGYG", board.Solved, board.Feedback)
+	}
+	if board.ShortlistReduction.After >= board.ShortlistReduction.Before || board.ShortlistReduction.Ratio <= 0 {
+		t.Errorf("board 1 reduction = %+v, want the shortlist narrowed", board.ShortlistReduction)
+	}
+}
+
+func TestEvaluateMultiHandler_NoGuessLimit(t *testing.T) {
+	reqBody := `{"solutions":["crane","slate"],"guesses":["moist","pudgy","audio","brick","fjord","lymph","vexed"],"max_guesses":-1,"proposed_guess":"whelk"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/evaluate/multi", strings.NewReader(reqBody))
+	w := httptest.NewRecorder()
+	evaluateMultiHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v (body %q)", w.Code, http.StatusOK, w.Body.String())
+	}
+	var resp MultiResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.GameStatus != "ongoing" || !resp.TurnValid || resp.GuessesRemaining != -1 {
+		t.Errorf("status %q, turn valid %v, %d guesses left, want ongoing, true, -1", resp.GameStatus, resp.TurnValid, resp.GuessesRemaining)
+	}
+}
+
+func TestEvaluateMultiHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		reqBody    string
+		wantStatus int
+		wantCode   string // Error.Code for a 400, or InvalidReason for a 200
+	}{
+		{"no boards", `{"solutions":[]}`, http.StatusBadRequest, CodeInvalidBoards},
+		{"negative budget", `{"solutions":["crane"],"max_guesses":-2}`, http.StatusBadRequest, CodeInvalidMaxGuesses},
+		{"repeated solution", `{"solutions":["crane","crane"]}`, http.StatusBadRequest, CodeInvalidBoards},
+		{"solution not allowed", `{"solutions":["crane","abcde"]}`, http.StatusBadRequest, ReasonNotInWordlist},
+		{"past guess not allowed", `{"solutions":["crane"],"guesses":["abcde"]}`, http.StatusBadRequest, ReasonNotInWordlist},
+		{"repeated proposed guess", `{"solutions":["crane","slate"],"guesses":["moist"],"proposed_guess":"moist"}`, http.StatusOK, ReasonRepeatedGuess},
+		{"out of guesses", `{"solutions":["crane","slate"],"guesses":["moist"],"max_guesses":1,"proposed_guess":"crane"}`, http.StatusOK, ReasonGameOver},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/evaluate/multi", strings.NewReader(tt.reqBody))
+			w := httptest.NewRecorder()
+			evaluateMultiHandler(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v (body %q)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus == http.StatusOK {
+				var resp MultiResponse
+				if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if resp.TurnValid || resp.InvalidReason != tt.wantCode {
+					t.Errorf("TurnValid = %v, InvalidReason = %q, want false, %q", resp.TurnValid, resp.InvalidReason, tt.wantCode)
+				}
+				return
+			}
+			var resp ErrorResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode error response: %v", err)
+			}
+			if resp.Error.Code != tt.wantCode {
+				t.Errorf("Error.Code = %q, want %q", resp.Error.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestEvaluateBatchHandler(t *testing.T) {
+	items := []string{
+		`{"solution":"crane","turns":[],"proposed_guess":"slate"}`,
+		`{"solution":"crane","turns":[{"guess":"slate","feedback":"--G-G"}],"proposed_guess":"crane"}`,
+		`{"solution":"abcde","turns":[],"proposed_guess":"slate"}`,
+		`{"solution":"crane","turns":[{"guess":"slate","feedback":"--G-G"}],"proposed_guess":"slate"}`,
+		`{"solution":"crane","turns":[{"guess":"slate","feedback":"GGGGG"}],"proposed_guess":""}`,
+	}
+	wordlegameengine.InitCache()
+	reqBody := "[" + strings.Join(items, ",") + "]"
+	req := httptest.NewRequest(http.MethodPost, "/api/evaluate/batch", strings.NewReader(reqBody))
+	w := httptest.NewRecorder()
+	evaluateBatchHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v (body %q)", w.Code, http.StatusOK, w.Body.String())
+	}
+	var resp []BatchItem
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp) != len(items) {
+		t.Fatalf("got %d items, want %d", len(resp), len(items))
+	}
+
+	// Each item is what /api/evaluate gives for the same request
+	for i, item := range items {
+		single := httptest.NewRecorder()
+		evaluateHandler(single, httptest.NewRequest(http.MethodPost, "/api/evaluate", strings.NewReader(item)))
+
+		if single.Code == http.StatusOK {
+			var want Response
+			if err := json.NewDecoder(single.Body).Decode(&want); err != nil {
+				t.Fatalf("item %d: failed to decode response: %v", i, err)
+			}
+			if resp[i].Response == nil || *resp[i].Response != want || resp[i].Error != nil {
+				t.Errorf("item %d = %+v, want %+v", i, resp[i], want)
+			}
+			continue
+		}
+		var want ErrorResponse
+		if err := json.NewDecoder(single.Body).Decode(&want); err != nil {
+			t.Fatalf("item %d: failed to decode error response: %v", i, err)
+		}
+		if resp[i].Response != nil || resp[i].Error == nil || resp[i].Error.Code != want.Error.Code || resp[i].Error.Message != want.Error.Message {
+			t.Errorf("item %d = %+v, want error %+v", i, resp[i], want.Error)
+		}
+	}
+
+	if resp[3].TurnValid || resp[3].InvalidReason != ReasonRepeatedGuess {
+		t.Errorf("item 3 TurnValid = %v, InvalidReason = %q, want false, %q", resp[3].TurnValid, resp[3].InvalidReason, ReasonRepeatedGuess)
+	}
+	if resp[4].Error == nil || resp[4].Error.Code != CodeFeedbackMismatch || resp[4].Error.Turn == nil || *resp[4].Error.Turn != 0 {
+		t.Errorf("item 4 error = %+v, want feedback_mismatch at turn 0", resp[4].Error)
+	}
+}
+
+func TestEvaluateBatchHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		reqBody    string
+		wantStatus int
+		wantCode   string
+	}{
+		{"empty batch", `[]`, http.StatusOK, ""},
+		{"not an array", `{"solution":"crane"}`, http.StatusBadRequest, CodeInvalidJSON},
+		{"too large", "[" + strings.Repeat(`{"solution":"crane"},`, maxBatchSize) + `{"solution":"crane"}]`, http.StatusBadRequest, CodeBatchTooLarge},
+		{"body too large", `[{"solution":"` + strings.Repeat("a", maxBatchSize*maxBatchItemBytes) + `"}]`, http.StatusRequestEntityTooLarge, CodeBodyTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/evaluate/batch", strings.NewReader(tt.reqBody))
+			w := httptest.NewRecorder()
+			evaluateBatchHandler(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v (body %q)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus == http.StatusOK {
+				if got := strings.TrimSpace(w.Body.String()); got != "[]" {
+					t.Errorf("body = %s, want []", got)
+				}
+				return
+			}
+			var resp ErrorResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode error response: %v", err)
+			}
+			if resp.Error.Code != tt.wantCode {
+				t.Errorf("Error.Code = %q, want %q", resp.Error.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestEvaluateBatch_Workers(t *testing.T) {
+	defer func(n int) { batchWorkers = n }(batchWorkers)
+
+	reqs := make([]Request, 50)
+	for i := range reqs {
+		reqs[i] = Request{Solution: "crane", Turns: []Turn{{Guess: "slate", Feedback: "--G-G"}}, ProposedGuess: "trace"}
+	}
+	for _, workers := range []int{0, 1, 4, 100} {
+		batchWorkers = workers
+		items := evaluateBatch(reqs)
+		for i, item := range items {
+			if item.Response == nil || item.Feedback != "-GGYG" {
+				t.Fatalf("%d workers: item %d = %+v, want feedback -GGYG", workers, i, item)
+			}
+		}
+	}
+}
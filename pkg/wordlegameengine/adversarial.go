@@ -0,0 +1,80 @@
+package wordlegameengine
+
+// AdversarialGame is played against an opponent that never commits to a solution, as in Absurdle.
+// After each guess it gives the feedback that keeps the most candidates on the shortlist, so the
+// game is only won once the guess is the last candidate left. It is an assistant-mode Game, whose
+// turns are added by the opponent rather than replayed. As in Absurdle, there is no guess limit
+// unless its Rules set one
+type AdversarialGame struct {
+	*Game
+}
+
+// NewAdversarialGame starts an adversarial game played against the default engine
+func NewAdversarialGame() *AdversarialGame {
+	return newAdversarialGame(NewAssistantGame())
+}
+
+// NewAdversarialGame starts an adversarial game with every allowed solution on the shortlist
+func (e *Engine) NewAdversarialGame() *AdversarialGame {
+	return newAdversarialGame(e.NewAssistantGame())
+}
+
+func newAdversarialGame(game *Game) *AdversarialGame {
+	game.Rules.MaxGuesses = NoGuessLimit
+	return &AdversarialGame{Game: game}
+}
+
+// PlayGuess adds guess to the game with the opponent's feedback, as given by WorstFeedback, and
+// returns that feedback. A guess the rules do not allow is rejected with the error from
+// ValidateGuess, and the game is left unchanged
+func (g *AdversarialGame) PlayGuess(guess Word) (Feedback, error) {
+	feedback := g.WorstFeedback(guess)
+	if err := g.ReplayTurn(guess, feedback); err != nil {
+		return Feedback{}, err
+	}
+	return feedback, nil
+}
+
+// WorstFeedback partitions the shortlist by the feedback each candidate gives for guess, and
+// returns the feedback of the largest part. Ties go to the feedback with the fewest greens, then
+// the fewest yellows, then the lowest encoding, so the opponent is deterministic and reveals as
+// little as it can
+func (g *AdversarialGame) WorstFeedback(guess Word) Feedback {
+	engine := g.Engine()
+	counts := make([]int, engine.NumFeedbacks())
+	for word := range g.SolutionShortlist.All() {
+		solution := Solution(word)
+		counts[solution.CheckGuess(guess).Encode()]++
+	}
+
+	best := -1
+	var bestGreens, bestYellows int
+	for code, count := range counts {
+		if count == 0 {
+			continue
+		}
+		greens, yellows := DecodeFeedback(uint16(code), engine.WordLength()).colorCounts()
+		// Codes are visited in increasing order, so an equal candidate never replaces the best
+		if best == -1 || count > counts[best] ||
+			(count == counts[best] && (greens < bestGreens || (greens == bestGreens && yellows < bestYellows))) {
+			best, bestGreens, bestYellows = code, greens, yellows
+		}
+	}
+	if best == -1 {
+		return DecodeFeedback(0, guess.Len()) // Empty shortlist: every tile grey
+	}
+	return DecodeFeedback(uint16(best), engine.WordLength())
+}
+
+// colorCounts returns the number of green and yellow tiles in the feedback
+func (f Feedback) colorCounts() (greens, yellows int) {
+	for _, color := range f[:f.Len()] {
+		switch color {
+		case Green:
+			greens++
+		case Yellow:
+			yellows++
+		}
+	}
+	return greens, yellows
+}
@@ -0,0 +1,90 @@
+package wordlegameengine
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAdversarialGame_WorstFeedback(t *testing.T) {
+	tests := []struct {
+		name          string
+		solutions     []string
+		guess         string
+		want          string
+		wantShortlist []string
+	}{
+		{"largest bucket", []string{"aifgh", "fghij", "klmno"}, "abcde", "-----", []string{"fghij", "klmno"}},
+		{"tie to fewest greens", []string{"abcde", "fghij"}, "abcde", "-----", []string{"fghij"}},
+		{"tie to fewest greens over yellows", []string{"aifgh", "bafgh"}, "abcde", "YY---", []string{"bafgh"}},
+		{"tie to fewest yellows", []string{"bafgh", "cfghi"}, "abcde", "--Y--", []string{"cfghi"}},
+		{"tie to lowest encoding", []string{"bfghi", "cfghi"}, "abcde", "--Y--", []string{"cfghi"}},
+		{"last candidate", []string{"abcde"}, "abcde", "GGGGG", []string{"abcde"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := mustNewEngine(t, append([]string{tt.guess}, tt.solutions...), tt.solutions)
+			game := e.NewAdversarialGame()
+
+			feedback, err := game.PlayGuess(mustNewWord(tt.guess))
+			if err != nil {
+				t.Fatalf("PlayGuess() error = %v", err)
+			}
+			if got := feedback.String(); got != tt.want {
+				t.Errorf("PlayGuess(%q) = %s, want %s", tt.guess, got, tt.want)
+			}
+			if got, want := game.SolutionShortlist.Words(), mustNewWords(tt.wantShortlist...); !slices.Equal(got, want) {
+				t.Errorf("SolutionShortlist = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestAdversarialGame_KeepsLargestShortlist(t *testing.T) {
+	game := NewAdversarialGame()
+	guess := mustNewWord("crane")
+
+	// Every other feedback would leave at most as many candidates
+	largest := 0
+	counts := make(map[Feedback]int)
+	for _, word := range AllowedSolutions {
+		solution := Solution(word)
+		feedback := solution.CheckGuess(guess)
+		counts[feedback]++
+		largest = max(largest, counts[feedback])
+	}
+
+	feedback, err := game.PlayGuess(guess)
+	if err != nil {
+		t.Fatalf("PlayGuess() error = %v", err)
+	}
+	if game.ShortlistLength() != largest || counts[feedback] != largest {
+		t.Errorf("shortlist has %d candidates after %s, want the largest bucket of %d", game.ShortlistLength(), feedback, largest)
+	}
+	if game.Status() != StatusOngoing {
+		t.Errorf("Status() = %v, want ongoing", game.Status())
+	}
+
+	// The opponent is deterministic
+	again, _ := NewAdversarialGame().PlayGuess(guess)
+	if again != feedback {
+		t.Errorf("second game gave %s, want %s", again, feedback)
+	}
+}
+
+func TestAdversarialGame_Won(t *testing.T) {
+	e := mustNewEngine(t, []string{"abcde", "fghij"}, []string{"abcde", "fghij"})
+	game := e.NewAdversarialGame()
+
+	for _, guess := range []string{"abcde", "fghij"} {
+		if err := game.ValidateGuess(mustNewWord(guess)); err != nil {
+			t.Fatalf("ValidateGuess(%q) error = %v", guess, err)
+		}
+		if _, err := game.PlayGuess(mustNewWord(guess)); err != nil {
+			t.Fatalf("PlayGuess(%q) error = %v", guess, err)
+		}
+	}
+	if game.Status() != StatusWon || len(game.Guesses) != 2 {
+		t.Errorf("Status() = %v after %d guesses, want won after 2", game.Status(), len(game.Guesses))
+	}
+}
@@ -0,0 +1,307 @@
+package wordlegameengine
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const cacheSnapshotMagic = "WSCS"
+const cacheSnapshotVersion = 2
+
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// decodedKey is a cache key decoded into its turn history
+type decodedKey struct {
+	guessIdxs []int
+	codes     []uint16
+}
+
+// Save writes the cache entries in a versioned binary format: magic, version, wordlist
+// fingerprint, number of solutions and of entries, the entries, then a CRC-32 of everything
+// before it. Each entry is its number of turns, each turn's guess as an AllowedGuesses index
+// (uvarint) and encoded feedback (uvarint), then its shortlist. Entries are written in the order
+// they would be evicted, so Load restores it. Keys that are not turn histories of allowed guesses are
+// skipped. The default engine's wordlists are used; see Engine.SaveCache
+func (c *ShortlistCache) Save(w io.Writer) error {
+	return Default().saveCache(c, w)
+}
+
+// SaveCache writes the engine's cache entries in the format of ShortlistCache.Save
+func (e *Engine) SaveCache(w io.Writer) error {
+	return e.saveCache(e.cache, w)
+}
+
+func (e *Engine) saveCache(c *ShortlistCache, w io.Writer) error {
+	if err := e.checkShortlistEncoding(); err != nil {
+		return err
+	}
+
+	// Collect the entries under the lock, and encode them after releasing it. Shortlists are
+	// immutable, so this needs no copying. Entries in eviction order come first, then those with
+	// their reference bit set, which eviction would have passed over
+	c.mutex.RLock()
+	entries := make([]CacheEntry, 0, c.clock.Len())
+	var referenced []CacheEntry
+	for elem := c.clock.Back(); elem != nil; elem = elem.Prev() {
+		ref := elem.Value.(*clockRef)
+		item := c.tree.Get(CacheEntry{Key: ref.key})
+		if item == nil {
+			continue
+		}
+		if ref.referenced.Load() {
+			referenced = append(referenced, item.(CacheEntry))
+		} else {
+			entries = append(entries, item.(CacheEntry))
+		}
+	}
+	entries = append(entries, referenced...)
+	c.mutex.RUnlock()
+
+	guessIndex := e.guessIndexes()
+
+	type savedEntry struct {
+		decoded   decodedKey
+		shortlist Shortlist
+	}
+	saved := make([]savedEntry, 0, len(entries))
+	for _, entry := range entries {
+		if decoded, ok := decodeCacheKey(entry.Key, guessIndex); ok {
+			saved = append(saved, savedEntry{decoded, entry.Shortlist})
+		}
+	}
+
+	// Everything written through cw counts towards the checksum
+	checksum := crc32.NewIEEE()
+	cw := io.MultiWriter(w, checksum)
+
+	header := make([]byte, 0, 22)
+	header = append(header, cacheSnapshotMagic...)
+	header = binary.LittleEndian.AppendUint16(header, cacheSnapshotVersion)
+	header = binary.LittleEndian.AppendUint64(header, e.fingerprint)
+	header = binary.LittleEndian.AppendUint32(header, uint32(len(e.solutions)))
+	header = binary.LittleEndian.AppendUint32(header, uint32(len(saved)))
+	if _, err := cw.Write(header); err != nil {
+		return err
+	}
+
+	var buf []byte
+	for _, entry := range saved {
+		buf = append(buf[:0], uint8(len(entry.decoded.guessIdxs)))
+		for i, guessIdx := range entry.decoded.guessIdxs {
+			buf = binary.AppendUvarint(buf, uint64(guessIdx))
+			buf = binary.AppendUvarint(buf, uint64(entry.decoded.codes[i]))
+		}
+		buf = e.appendShortlist(buf, entry.shortlist)
+		if _, err := cw.Write(buf); err != nil {
+			return err
+		}
+	}
+
+	_, err := w.Write(binary.LittleEndian.AppendUint32(nil, checksum.Sum32()))
+	return err
+}
+
+// decodeCacheKey parses a key made by MakeTurnsKey into guess indexes and feedback codes
+func decodeCacheKey(key CacheKey, guessIndex map[Word]int) (decodedKey, bool) {
+	turns := strings.Split(string(key), turnSeparator)
+	if len(turns) > 255 {
+		return decodedKey{}, false
+	}
+
+	var decoded decodedKey
+	for _, turn := range turns {
+		guessStr, feedbackStr, found := strings.Cut(turn, "|")
+		if !found {
+			return decodedKey{}, false
+		}
+		guess, err := NewWord(guessStr)
+		if err != nil {
+			return decodedKey{}, false
+		}
+		guessIdx, ok := guessIndex[guess]
+		if !ok {
+			return decodedKey{}, false
+		}
+		feedback, err := ParseFeedback(feedbackStr)
+		if err != nil {
+			return decodedKey{}, false
+		}
+		decoded.guessIdxs = append(decoded.guessIdxs, guessIdx)
+		decoded.codes = append(decoded.codes, feedback.Encode())
+	}
+	return decoded, true
+}
+
+// Load reads entries written by Save into the cache. Nothing is added unless the whole snapshot
+// is read and its checksum matches; ErrWordlistMismatch is returned for a snapshot made against
+// other wordlists. The default engine's wordlists are used; see Engine.LoadCache
+func (c *ShortlistCache) Load(r io.Reader) error {
+	return Default().loadCache(c, r)
+}
+
+// LoadCache reads entries written by SaveCache into the engine's cache
+func (e *Engine) LoadCache(r io.Reader) error {
+	return e.loadCache(e.cache, r)
+}
+
+func (e *Engine) loadCache(c *ShortlistCache, r io.Reader) error {
+	cr := &checksumReader{r: bufio.NewReader(r), crc: crc32.NewIEEE()}
+
+	header := make([]byte, 22)
+	if _, err := io.ReadFull(cr, header); err != nil {
+		return fmt.Errorf("reading cache snapshot header: %w", err)
+	}
+	if string(header[:4]) != cacheSnapshotMagic {
+		return fmt.Errorf("not a cache snapshot")
+	}
+	if version := binary.LittleEndian.Uint16(header[4:6]); version != cacheSnapshotVersion {
+		return fmt.Errorf("unsupported cache snapshot version %d", version)
+	}
+	if binary.LittleEndian.Uint64(header[6:14]) != e.fingerprint ||
+		int(binary.LittleEndian.Uint32(header[14:18])) != len(e.solutions) {
+		return fmt.Errorf("cache snapshot: %w", ErrWordlistMismatch)
+	}
+	numEntries := int(binary.LittleEndian.Uint32(header[18:22]))
+
+	entries := make([]CacheEntry, 0, min(numEntries, 1<<20))
+	for i := 0; i < numEntries; i++ {
+		entry, err := e.readSnapshotEntry(cr)
+		if err != nil {
+			return fmt.Errorf("reading cache snapshot entry %d: %w", i, err)
+		}
+		entries = append(entries, entry)
+	}
+
+	// The checksum covers everything before it
+	want := cr.crc.Sum32()
+	var sum [4]byte
+	if _, err := io.ReadFull(cr.r, sum[:]); err != nil {
+		return fmt.Errorf("reading cache snapshot checksum: %w", err)
+	}
+	if binary.LittleEndian.Uint32(sum[:]) != want {
+		return fmt.Errorf("cache snapshot: %w", ErrChecksumMismatch)
+	}
+
+	for _, entry := range entries {
+		c.Put(entry.Key, entry.Shortlist)
+	}
+	return nil
+}
+
+// readSnapshotEntry reads one entry written by Save
+func (e *Engine) readSnapshotEntry(r *checksumReader) (CacheEntry, error) {
+	numTurns, err := r.ReadByte()
+	if err != nil {
+		return CacheEntry{}, err
+	}
+	if numTurns == 0 {
+		return CacheEntry{}, fmt.Errorf("entry has no turns")
+	}
+
+	guesses := make([]Word, numTurns)
+	feedbacks := make([]Feedback, numTurns)
+	for i := range guesses {
+		guessIdx, err := binary.ReadUvarint(r)
+		if err != nil {
+			return CacheEntry{}, err
+		}
+		if guessIdx >= uint64(len(e.guesses)) {
+			return CacheEntry{}, fmt.Errorf("invalid guess index %d", guessIdx)
+		}
+		code, err := binary.ReadUvarint(r)
+		if err != nil {
+			return CacheEntry{}, err
+		}
+		if code >= uint64(e.numFeedbacks) {
+			return CacheEntry{}, fmt.Errorf("invalid feedback code %d", code)
+		}
+		guesses[i] = e.guesses[guessIdx]
+		feedbacks[i] = DecodeFeedback(uint16(code), e.wordLength)
+	}
+
+	shortlist, err := e.readShortlist(r)
+	if err != nil {
+		return CacheEntry{}, err
+	}
+	return CacheEntry{Key: MakeTurnsKey(guesses, feedbacks), Shortlist: shortlist}, nil
+}
+
+// checksumReader computes the CRC-32 of the bytes read through it
+type checksumReader struct {
+	r   *bufio.Reader
+	crc hash.Hash32
+}
+
+func (cr *checksumReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.crc.Write(p[:n])
+	return n, err
+}
+
+func (cr *checksumReader) ReadByte() (byte, error) {
+	b, err := cr.r.ReadByte()
+	if err == nil {
+		cr.crc.Write([]byte{b})
+	}
+	return b, err
+}
+
+// SaveFile writes a snapshot of the cache to path. The snapshot is written to a temporary file
+// first and renamed into place, so an interrupted save leaves any previous snapshot intact
+func (c *ShortlistCache) SaveFile(path string) error {
+	return Default().saveCacheFile(c, path)
+}
+
+// SaveCacheFile writes a snapshot of the engine's cache to path, like ShortlistCache.SaveFile
+func (e *Engine) SaveCacheFile(path string) error {
+	return e.saveCacheFile(e.cache, path)
+}
+
+func (e *Engine) saveCacheFile(c *ShortlistCache, path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+
+	w := bufio.NewWriter(tmp)
+	if err := e.saveCache(c, w); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// LoadFile reads a snapshot written by SaveFile into the cache
+func (c *ShortlistCache) LoadFile(path string) error {
+	return Default().loadCacheFile(c, path)
+}
+
+// LoadCacheFile reads a snapshot written by SaveCacheFile into the engine's cache
+func (e *Engine) LoadCacheFile(path string) error {
+	return e.loadCacheFile(e.cache, path)
+}
+
+func (e *Engine) loadCacheFile(c *ShortlistCache, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return e.loadCache(c, file)
+}
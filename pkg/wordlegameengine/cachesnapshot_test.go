@@ -0,0 +1,164 @@
+package wordlegameengine
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestShortlistCache_SaveAndLoad(t *testing.T) {
+	guesses := []Word{mustNewWord("raise"), mustNewWord("clout")}
+	feedbacks := []Feedback{{Grey, Green, Grey, Grey, Grey}, {Grey, Grey, Yellow, Grey, Grey}}
+	oneTurn := MakeTurnsKey(guesses[:1], feedbacks[:1])
+	twoTurns := MakeTurnsKey(guesses, feedbacks)
+	small := NewShortlist([]Word{mustNewWord("crane"), mustNewWord("stare")})
+
+	cache := NewShortlistCache()
+	cache.Put(oneTurn, FullShortlist())
+	cache.Put(twoTurns, small)
+	cache.Put("not a turn history", small)
+
+	var buf bytes.Buffer
+	if err := cache.Save(&buf); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded := NewShortlistCache()
+	if err := loaded.Load(&buf); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	for _, key := range []CacheKey{oneTurn, twoTurns} {
+		want, _ := cache.Get(key)
+		got, found := loaded.Get(key)
+		if !found {
+			t.Fatalf("%q not restored", key)
+		}
+		if !slices.Equal(got.Words(), want.Words()) {
+			t.Errorf("%q restored as %v, want %v", key, got.Words(), want.Words())
+		}
+	}
+	if _, found := loaded.Get("not a turn history"); found {
+		t.Error("Expected a key that is not a turn history to be skipped")
+	}
+}
+
+func TestShortlistCache_SaveAndLoad_KeepsRecency(t *testing.T) {
+	shortlist := NewShortlist([]Word{mustNewWord("crane")})
+	keyA := MakeCacheKey(mustNewWord("raise"), allGrey)
+	keyB := MakeCacheKey(mustNewWord("slate"), allGrey)
+	keyC := MakeCacheKey(mustNewWord("crane"), allGrey)
+
+	cache := NewShortlistCache()
+	cache.Put(keyA, shortlist)
+	cache.Put(keyB, shortlist)
+	cache.Get(keyA) // B is now the least recently used
+
+	var buf bytes.Buffer
+	cache.Save(&buf)
+
+	// Room for two entries: adding a third evicts B, as it would have in the original cache
+	loaded := NewShortlistCacheWithBudget(2 * CacheEntry{Key: keyA, Shortlist: shortlist}.size())
+	if err := loaded.Load(&buf); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	loaded.Put(keyC, shortlist)
+
+	if _, found := loaded.Get(keyB); found {
+		t.Error("Expected the least recently used entry to be evicted after Load")
+	}
+	if _, found := loaded.Get(keyA); !found {
+		t.Error("Expected the most recently used entry to remain after Load")
+	}
+}
+
+func TestShortlistCache_Load_Invalid(t *testing.T) {
+	cache := NewShortlistCache()
+	cache.Put(MakeCacheKey(mustNewWord("raise"), allGrey), FullShortlist())
+	var buf bytes.Buffer
+	cache.Save(&buf)
+	valid := buf.Bytes()
+
+	corrupt := func(i int) []byte {
+		data := append([]byte{}, valid...)
+		data[i] ^= 0x01
+		return data
+	}
+	// Corrupts a byte and writes a matching checksum, as a buggy writer would
+	resigned := func(i int) []byte {
+		data := corrupt(i)
+		binary.LittleEndian.PutUint32(data[len(data)-4:], crc32.ChecksumIEEE(data[:len(data)-4]))
+		return data
+	}
+	lastWord := len(valid) - 4 - 8
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"empty", nil, nil},
+		{"bad magic", corrupt(0), nil},
+		{"other wordlists", corrupt(6), ErrWordlistMismatch},
+		{"corrupted key", corrupt(23), ErrChecksumMismatch},
+		{"corrupted shortlist", corrupt(len(valid) - 5), nil},
+		{"truncated", valid[:len(valid)-1], nil},
+		{"padding bit with a valid checksum", resigned(len(valid) - 5), nil},
+		{"wrong count with a valid checksum", resigned(lastWord), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := NewShortlistCache()
+			err := target.Load(bytes.NewReader(tt.data))
+			if err == nil {
+				t.Fatal("Load() error = nil, want error")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("Load() error = %v, want %v", err, tt.want)
+			}
+			if entries := target.Stats().Entries; entries != 0 {
+				t.Errorf("Load() added %d entries from an invalid snapshot", entries)
+			}
+		})
+	}
+}
+
+func TestEngine_SaveCache_TooManySolutions(t *testing.T) {
+	e := mustNewTooManySolutionsEngine(t)
+	var buf bytes.Buffer
+	if err := e.SaveCache(&buf); !errors.Is(err, ErrTooManySolutions) {
+		t.Errorf("SaveCache() error = %v, want ErrTooManySolutions", err)
+	}
+}
+
+func TestShortlistCache_SaveFileAndLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cache.bin")
+	key := MakeCacheKey(mustNewWord("raise"), allGrey)
+
+	cache := NewShortlistCache()
+	cache.Put(key, NewShortlist([]Word{mustNewWord("crane")}))
+	if err := cache.SaveFile(path); err != nil {
+		t.Fatalf("SaveFile() error = %v", err)
+	}
+
+	loaded := NewShortlistCache()
+	if err := loaded.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if _, found := loaded.Get(key); !found {
+		t.Errorf("%q not restored", key)
+	}
+
+	// Only the snapshot is left behind, not temporary files
+	files, _ := os.ReadDir(dir)
+	if len(files) != 1 {
+		t.Errorf("SaveFile() left %d files, want 1", len(files))
+	}
+}
@@ -0,0 +1,282 @@
+package wordlegameengine
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/rand/v2"
+	"os"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sam-bee/wordle-game-engine/data"
+)
+
+var (
+	ErrEmptyWordlist    = errors.New("wordlist is empty")
+	ErrMixedWordLengths = errors.New("words have different lengths")
+)
+
+// Engine owns a word set and everything derived from it: the shortlist cache, the optional
+// precomputed feedback table and the memoised feedback masks. Games, shortlists and validation
+// created by an engine use only its wordlists, so engines with different word sets or word
+// lengths can be used side by side. An Engine is safe for concurrent use
+type Engine struct {
+	guesses      []Word // Sorted
+	solutions    []Word // Sorted
+	wordLength   int
+	numFeedbacks int
+	fingerprint  uint64
+	cache        *ShortlistCache
+	table        *FeedbackTable
+	masks        *maskMemo
+}
+
+// EngineConfig configures an Engine
+type EngineConfig struct {
+	CacheBytes         int64          // Shortlist cache budget; 0 means unbounded
+	FeedbackTable      *FeedbackTable // Optional precomputed feedback, e.g. from LoadFeedbackTable
+	BuildFeedbackTable bool           // Compute the feedback table if none is given
+}
+
+// DefaultEngineConfig returns the configuration used by NewEngine
+func DefaultEngineConfig() EngineConfig {
+	return EngineConfig{CacheBytes: DefaultCacheBytes}
+}
+
+// NewEngine creates an engine for the given wordlists with the default configuration. The lists
+// are copied, sorted and deduplicated
+func NewEngine(guesses, solutions []Word) (*Engine, error) {
+	return NewEngineWithConfig(guesses, solutions, DefaultEngineConfig())
+}
+
+// NewEngineWithConfig creates an engine for the given wordlists. The lists are copied, sorted and
+// deduplicated, and every word must have the same length, which becomes the engine's word length.
+// A feedback table in the config must have been built for the sorted lists
+func NewEngineWithConfig(guesses, solutions []Word, config EngineConfig) (*Engine, error) {
+	if len(guesses) == 0 {
+		return nil, fmt.Errorf("allowed guesses: %w", ErrEmptyWordlist)
+	}
+	if len(solutions) == 0 {
+		return nil, fmt.Errorf("allowed solutions: %w", ErrEmptyWordlist)
+	}
+	length := guesses[0].Len()
+	for _, list := range [][]Word{guesses, solutions} {
+		for _, w := range list {
+			if w.Len() != length {
+				return nil, fmt.Errorf("%q and %q: %w", guesses[0].String(), w.String(), ErrMixedWordLengths)
+			}
+		}
+	}
+	guesses = slices.Compact(slices.SortedFunc(slices.Values(guesses), Word.Compare))
+	solutions = slices.Compact(slices.SortedFunc(slices.Values(solutions), Word.Compare))
+
+	e := newEngine(guesses, solutions, NewShortlistCacheWithBudget(config.CacheBytes), nil, nil)
+	switch {
+	case config.FeedbackTable != nil:
+		if err := config.FeedbackTable.checkWordlists(guesses, solutions); err != nil {
+			return nil, err
+		}
+		e.table = config.FeedbackTable
+	case config.BuildFeedbackTable:
+		e.table = NewFeedbackTable(guesses, solutions)
+	}
+	return e, nil
+}
+
+// LoadEngine creates an engine with the default configuration from the GuessesFile and
+// SolutionsFile in dataDir
+func LoadEngine(dataDir string) (*Engine, error) {
+	e, err := LoadEngineFS(os.DirFS(dataDir))
+	if err != nil {
+		return nil, fmt.Errorf("loading wordlists from %s: %w", dataDir, err)
+	}
+	return e, nil
+}
+
+// LoadEngineFS creates an engine with the default configuration from the GuessesFile and
+// SolutionsFile in fsys
+func LoadEngineFS(fsys fs.FS) (*Engine, error) {
+	guesses, solutions, err := loadWordlists(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return NewEngine(guesses, solutions)
+}
+
+// LoadDefaultEngine creates an engine with the default configuration from the bundled wordlists
+func LoadDefaultEngine() (*Engine, error) {
+	return LoadEngineFS(data.FS)
+}
+
+func newEngine(guesses, solutions []Word, cache *ShortlistCache, table *FeedbackTable, masks *maskMemo) *Engine {
+	if masks == nil {
+		masks = newMaskMemo(maxMemoisedGuesses)
+	}
+
+	// The length of the first word; wordlists of mixed lengths are rejected by NewEngine
+	length := DefaultWordLength
+	if len(solutions) > 0 {
+		length = solutions[0].Len()
+	} else if len(guesses) > 0 {
+		length = guesses[0].Len()
+	}
+
+	return &Engine{
+		guesses:      guesses,
+		solutions:    solutions,
+		wordLength:   length,
+		numFeedbacks: NumFeedbacks(length),
+		fingerprint:  wordlistFingerprint(guesses, solutions),
+		cache:        cache,
+		table:        table,
+		masks:        masks,
+	}
+}
+
+// defaultEngine is the engine behind the package-level API. It is read without the lock while it
+// matches the package-level variables; the lock is only taken to rebuild it
+var defaultEngine struct {
+	sync.Mutex
+	engine atomic.Pointer[Engine]
+}
+
+// Default returns the engine behind the package-level API, built from AllowedGuesses,
+// AllowedSolutions, FirstTurnCache and PrecomputedFeedback. It follows changes to those variables, so
+// it should be configured through LoadWordlists, InitCache and InitFeedbackTable
+func Default() *Engine {
+	if e := defaultEngine.engine.Load(); e.isDefault() {
+		return e
+	}
+
+	defaultEngine.Lock()
+	defer defaultEngine.Unlock()
+
+	// Another caller may have rebuilt it while this one waited for the lock
+	e := defaultEngine.engine.Load()
+	if e.isDefault() {
+		return e
+	}
+	sameWordlists := e.hasDefaultWordlists()
+
+	// Feedback masks depend only on the wordlists, so are kept when just the cache or table changes
+	var masks *maskMemo
+	if sameWordlists {
+		masks = e.masks
+	}
+	e = newEngine(AllowedGuesses, AllowedSolutions, FirstTurnCache, PrecomputedFeedback, masks)
+	defaultEngine.engine.Store(e)
+	return e
+}
+
+// isDefault reports whether e was built from the current package-level wordlists, cache and table
+func (e *Engine) isDefault() bool {
+	return e.hasDefaultWordlists() && e.cache == FirstTurnCache && e.table == PrecomputedFeedback
+}
+
+// hasDefaultWordlists reports whether e uses AllowedGuesses and AllowedSolutions. It is false for a
+// nil engine
+func (e *Engine) hasDefaultWordlists() bool {
+	return e != nil && sameWordlist(e.guesses, AllowedGuesses) && sameWordlist(e.solutions, AllowedSolutions)
+}
+
+// Guesses returns the engine's allowed guesses, sorted. The slice must not be modified
+func (e *Engine) Guesses() []Word {
+	return e.guesses
+}
+
+// Solutions returns the engine's allowed solutions, sorted. The slice must not be modified
+func (e *Engine) Solutions() []Word {
+	return e.solutions
+}
+
+// WordLength returns the length of the engine's words
+func (e *Engine) WordLength() int {
+	return e.wordLength
+}
+
+// NumFeedbacks returns the number of distinct feedback patterns for the engine's words
+func (e *Engine) NumFeedbacks() int {
+	return e.numFeedbacks
+}
+
+// Cache returns the engine's shortlist cache
+func (e *Engine) Cache() *ShortlistCache {
+	return e.cache
+}
+
+// FeedbackTable returns the engine's precomputed feedback table, or nil if it has none
+func (e *Engine) FeedbackTable() *FeedbackTable {
+	return e.table
+}
+
+// guessIndexes maps each allowed guess to its position in the engine's guess list
+func (e *Engine) guessIndexes() map[Word]int {
+	guessIndex := make(map[Word]int, len(e.guesses))
+	for i, word := range e.guesses {
+		guessIndex[word] = i
+	}
+	return guessIndex
+}
+
+// ValidateWord checks that w is lowercase a-z, of the engine's word length and one of its allowed
+// guesses
+func (e *Engine) ValidateWord(w Word) error {
+	s := w.String()
+	if len(s) != e.wordLength {
+		return errInvalidLength(FieldGuess, s, e.wordLength)
+	}
+	if err := validateCharacters(s, FieldGuess); err != nil {
+		return err
+	}
+	if _, ok := slices.BinarySearchFunc(e.guesses, w, Word.Compare); !ok {
+		return errNotInWordlist(s)
+	}
+	return nil
+}
+
+// ValidateSolution checks that s is lowercase a-z, of the engine's word length and one of its
+// allowed solutions
+func (e *Engine) ValidateSolution(s Solution) error {
+	str := s.String()
+	if len(str) != e.wordLength {
+		return errInvalidLength(FieldSolution, str, e.wordLength)
+	}
+	if err := validateCharacters(str, FieldSolution); err != nil {
+		return err
+	}
+	if _, ok := e.solutionIndex(Word(s)); !ok {
+		return errNotInSolutions(str)
+	}
+	return nil
+}
+
+// NewGame starts a game with the given solution, with every allowed solution on the shortlist
+func (e *Engine) NewGame(solution Solution) *Game {
+	return e.NewGameWithShortlist(solution, e.FullShortlist())
+}
+
+// NewGameWithShortlist creates a game with a pre-populated solution shortlist
+// Used when loading from cache
+func (e *Engine) NewGameWithShortlist(solution Solution, shortlist Shortlist) *Game {
+	return newGame(e, solution, shortlist)
+}
+
+// NewAssistantGame creates a game whose solution is unknown, e.g. to help with a live game.
+// Turns are added with ReplayTurn, and SolutionShortlist holds the remaining candidates
+func (e *Engine) NewAssistantGame() *Game {
+	return e.NewGame(Solution{})
+}
+
+// NewGameWithRules starts a game under the given rules, with every allowed solution on the
+// shortlist. The zero Solution gives an assistant game
+func (e *Engine) NewGameWithRules(solution Solution, rules Rules) (*Game, error) {
+	return newGameWithRules(e, solution, rules)
+}
+
+// NewRandomGame starts a game with a solution picked at random from the allowed solutions
+func (e *Engine) NewRandomGame() *Game {
+	idx := rand.IntN(len(e.solutions))
+	return e.NewGame(Solution(e.solutions[idx]))
+}
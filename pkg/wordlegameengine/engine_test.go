@@ -0,0 +1,356 @@
+package wordlegameengine
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func mustNewWords(words ...string) []Word {
+	result := make([]Word, len(words))
+	for i, w := range words {
+		result[i] = mustNewWord(w)
+	}
+	return result
+}
+
+func mustNewEngine(t *testing.T, guesses, solutions []string) *Engine {
+	t.Helper()
+	e, err := NewEngine(mustNewWords(guesses...), mustNewWords(solutions...))
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+	return e
+}
+
+func TestNewEngine(t *testing.T) {
+	e := mustNewEngine(t, []string{"stare", "crane", "apple", "crane"}, []string{"stare", "apple"})
+
+	if got, want := e.Guesses(), mustNewWords("apple", "crane", "stare"); !slices.Equal(got, want) {
+		t.Errorf("Guesses() = %v, want %v", got, want)
+	}
+	if got, want := e.Solutions(), mustNewWords("apple", "stare"); !slices.Equal(got, want) {
+		t.Errorf("Solutions() = %v, want %v", got, want)
+	}
+	if e.Cache() == nil {
+		t.Error("Cache() = nil, want a cache")
+	}
+	if e.FeedbackTable() != nil {
+		t.Error("FeedbackTable() != nil, want no table by default")
+	}
+}
+
+func TestNewEngine_EmptyWordlist(t *testing.T) {
+	tests := []struct {
+		name      string
+		guesses   []Word
+		solutions []Word
+	}{
+		{"no guesses", nil, mustNewWords("apple")},
+		{"no solutions", mustNewWords("apple"), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewEngine(tt.guesses, tt.solutions); !errors.Is(err, ErrEmptyWordlist) {
+				t.Errorf("NewEngine() error = %v, want ErrEmptyWordlist", err)
+			}
+		})
+	}
+}
+
+func TestNewEngine_MixedWordLengths(t *testing.T) {
+	_, err := NewEngine(mustNewWords("apple", "crane"), mustNewWords("apple", "orange"))
+	if !errors.Is(err, ErrMixedWordLengths) {
+		t.Errorf("NewEngine() error = %v, want ErrMixedWordLengths", err)
+	}
+}
+
+func TestEngine_WordLengths(t *testing.T) {
+	tests := []struct {
+		name        string
+		guesses     []string
+		solutions   []string
+		solution    string
+		guess       string
+		shortlist   []string
+		wrongLength string
+	}{
+		{
+			name:        "four letters",
+			guesses:     []string{"lamp", "palm", "pale", "plea", "leap"},
+			solutions:   []string{"lamp", "palm", "pale", "leap"},
+			solution:    "leap",
+			guess:       "pale",
+			shortlist:   []string{"leap"},
+			wrongLength: "pales",
+		},
+		{
+			name:        "six letters",
+			guesses:     []string{"garden", "danger", "ranged", "orange", "onager"},
+			solutions:   []string{"garden", "danger", "ranged", "orange"},
+			solution:    "danger",
+			guess:       "garden",
+			shortlist:   []string{"danger", "ranged"},
+			wrongLength: "grand",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := mustNewEngine(t, tt.guesses, tt.solutions)
+			length := len(tt.solution)
+			if e.WordLength() != length || e.NumFeedbacks() != NumFeedbacks(length) {
+				t.Errorf("WordLength(), NumFeedbacks() = %d, %d, want %d, %d", e.WordLength(), e.NumFeedbacks(), length, NumFeedbacks(length))
+			}
+
+			wrongLength := mustNewWord(tt.wrongLength)
+			var validationErr *ValidationError
+			if err := e.ValidateWord(wrongLength); !errors.As(err, &validationErr) || validationErr.Length != length {
+				t.Errorf("ValidateWord(%q) error = %v, want ErrInvalidLength for %d letters", tt.wrongLength, err, length)
+			}
+
+			game := e.NewGame(mustNewSolution(tt.solution))
+			if err := game.PlayGuess(mustNewWord(tt.guess)); err != nil {
+				t.Fatalf("PlayGuess() error = %v", err)
+			}
+			if got := game.Feedbacks[0].Len(); got != length {
+				t.Errorf("feedback has %d tiles, want %d", got, length)
+			}
+			if got, want := game.SolutionShortlist.Words(), mustNewWords(tt.shortlist...); !slices.Equal(got, want) {
+				t.Errorf("SolutionShortlist = %v, want %v", got, want)
+			}
+
+			assistant := e.NewAssistantGame()
+			if err := assistant.ReplayTurnCached(e.Cache(), mustNewWord(tt.guess), game.Feedbacks[0]); err != nil {
+				t.Fatalf("ReplayTurnCached() error = %v", err)
+			}
+			if got, want := assistant.SolutionShortlist.Words(), mustNewWords(tt.shortlist...); !slices.Equal(got, want) {
+				t.Errorf("assistant SolutionShortlist = %v, want %v", got, want)
+			}
+
+			// Snapshots round-trip feedback of the engine's length
+			var buf bytes.Buffer
+			if err := e.SaveCache(&buf); err != nil {
+				t.Fatalf("SaveCache() error = %v", err)
+			}
+			restored := mustNewEngine(t, tt.guesses, tt.solutions)
+			if err := restored.LoadCache(&buf); err != nil {
+				t.Fatalf("LoadCache() error = %v", err)
+			}
+			if restored.Cache().Stats().Entries != e.Cache().Stats().Entries {
+				t.Errorf("restored %d entries, want %d", restored.Cache().Stats().Entries, e.Cache().Stats().Entries)
+			}
+		})
+	}
+}
+
+func TestNewEngineWithConfig(t *testing.T) {
+	guesses := mustNewWords("apple", "crane", "stare")
+	solutions := mustNewWords("apple", "stare")
+
+	e, err := NewEngineWithConfig(guesses, solutions, EngineConfig{CacheBytes: 1 << 20, BuildFeedbackTable: true})
+	if err != nil {
+		t.Fatalf("NewEngineWithConfig() error = %v", err)
+	}
+	if e.Cache().Stats().MaxBytes != 1<<20 {
+		t.Errorf("cache budget = %d, want %d", e.Cache().Stats().MaxBytes, 1<<20)
+	}
+	if e.FeedbackTable() == nil {
+		t.Fatal("FeedbackTable() = nil, want a built table")
+	}
+
+	// A table built for the engine's wordlists can be reused
+	if _, err := NewEngineWithConfig(guesses, solutions, EngineConfig{FeedbackTable: e.FeedbackTable()}); err != nil {
+		t.Errorf("NewEngineWithConfig() with matching table error = %v", err)
+	}
+
+	// A table built for other wordlists is rejected
+	other := NewFeedbackTable(guesses, mustNewWords("apple"))
+	_, err = NewEngineWithConfig(guesses, solutions, EngineConfig{FeedbackTable: other})
+	if !errors.Is(err, ErrWordlistMismatch) {
+		t.Errorf("NewEngineWithConfig() with other table error = %v, want ErrWordlistMismatch", err)
+	}
+}
+
+func TestLoadEngine(t *testing.T) {
+	e, err := LoadEngine("../../data")
+	if err != nil {
+		t.Fatalf("LoadEngine() error = %v", err)
+	}
+	if !slices.Equal(e.Guesses(), AllowedGuesses) || !slices.Equal(e.Solutions(), AllowedSolutions) {
+		t.Error("LoadEngine() wordlists differ from LoadWordlists()")
+	}
+
+	if _, err := LoadEngine(t.TempDir()); err == nil {
+		t.Error("LoadEngine() error = nil, want error for missing files")
+	}
+}
+
+func TestLoadDefaultEngine(t *testing.T) {
+	e, err := LoadDefaultEngine()
+	if err != nil {
+		t.Fatalf("LoadDefaultEngine() error = %v", err)
+	}
+	if len(e.Guesses()) != 14855 || len(e.Solutions()) != 2309 {
+		t.Errorf("LoadDefaultEngine() has %d guesses, %d solutions, want 14855, 2309", len(e.Guesses()), len(e.Solutions()))
+	}
+}
+
+func TestEngine_Validate(t *testing.T) {
+	e := mustNewEngine(t, []string{"apple", "crane", "stare"}, []string{"apple"})
+
+	tests := []struct {
+		name    string
+		err     error
+		wantErr error
+	}{
+		{"allowed guess", e.ValidateWord(mustNewWord("crane")), nil},
+		{"guess not in wordlist", e.ValidateWord(mustNewWord("slate")), ErrNotInWordlist},
+		{"invalid character", e.ValidateWord(Word{'c', 'r', 'a', 'n', 'E'}), ErrInvalidCharacter},
+		{"allowed solution", e.ValidateSolution(mustNewSolution("apple")), nil},
+		{"guess that cannot be the solution", e.ValidateSolution(mustNewSolution("crane")), ErrNotInWordlist},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !errors.Is(tt.err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", tt.err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEngine_SideBySide(t *testing.T) {
+	engines := []struct {
+		name      string
+		engine    *Engine
+		solution  string
+		guess     string
+		shortlist []string
+	}{
+		{
+			name:      "fruit",
+			engine:    mustNewEngine(t, []string{"apple", "grape", "lemon", "mango"}, []string{"apple", "grape", "mango"}),
+			solution:  "mango",
+			guess:     "grape",
+			shortlist: []string{"mango"},
+		},
+		{
+			name:      "birds",
+			engine:    mustNewEngine(t, []string{"crane", "egret", "raven", "robin"}, []string{"crane", "raven", "robin"}),
+			solution:  "raven",
+			guess:     "crane",
+			shortlist: []string{"raven"},
+		},
+	}
+
+	for _, tt := range engines {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			game := tt.engine.NewGame(mustNewSolution(tt.solution))
+			if game.Engine() != tt.engine {
+				t.Error("Engine() is not the engine that created the game")
+			}
+			if err := game.ValidateGuess(mustNewWord(tt.guess)); err != nil {
+				t.Fatalf("ValidateGuess() error = %v", err)
+			}
+			if err := game.ValidateGuess(mustNewWord("slate")); !errors.Is(err, ErrNotInWordlist) {
+				t.Errorf("ValidateGuess() error = %v, want ErrNotInWordlist for a word of the default engine", err)
+			}
+			if err := game.PlayGuess(mustNewWord(tt.guess)); err != nil {
+				t.Fatalf("PlayGuess() error = %v", err)
+			}
+			if got, want := game.SolutionShortlist.Words(), mustNewWords(tt.shortlist...); !slices.Equal(got, want) {
+				t.Errorf("SolutionShortlist = %v, want %v", got, want)
+			}
+
+			assistant := tt.engine.NewAssistantGame()
+			err := assistant.ReplayTurnCached(tt.engine.Cache(), mustNewWord(tt.guess), game.Feedbacks[0])
+			if err != nil {
+				t.Fatalf("ReplayTurnCached() error = %v", err)
+			}
+			if got, want := assistant.SolutionShortlist.Words(), mustNewWords(tt.shortlist...); !slices.Equal(got, want) {
+				t.Errorf("assistant SolutionShortlist = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestEngine_CacheSnapshotWordlists(t *testing.T) {
+	a := mustNewEngine(t, []string{"apple", "grape", "mango"}, []string{"apple", "mango"})
+	b := mustNewEngine(t, []string{"crane", "raven"}, []string{"crane", "raven"})
+
+	game := a.NewAssistantGame()
+	solution := mustNewSolution("mango")
+	if err := game.ReplayTurnCached(a.Cache(), mustNewWord("grape"), solution.CheckGuess(mustNewWord("grape"))); err != nil {
+		t.Fatalf("ReplayTurnCached() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := a.SaveCache(&buf); err != nil {
+		t.Fatalf("SaveCache() error = %v", err)
+	}
+	snapshot := buf.Bytes()
+
+	if err := b.LoadCache(bytes.NewReader(snapshot)); !errors.Is(err, ErrWordlistMismatch) {
+		t.Errorf("LoadCache() into another engine error = %v, want ErrWordlistMismatch", err)
+	}
+
+	restored := mustNewEngine(t, []string{"apple", "grape", "mango"}, []string{"apple", "mango"})
+	if err := restored.LoadCache(bytes.NewReader(snapshot)); err != nil {
+		t.Fatalf("LoadCache() error = %v", err)
+	}
+	if restored.Cache().Stats().Entries != 1 {
+		t.Errorf("restored cache has %d entries, want 1", restored.Cache().Stats().Entries)
+	}
+}
+
+func TestDefault(t *testing.T) {
+	oldGuesses, oldTable := AllowedGuesses, PrecomputedFeedback
+	defer func() { AllowedGuesses, PrecomputedFeedback = oldGuesses, oldTable }()
+
+	e := Default()
+	if Default() != e {
+		t.Error("Default() changed although the package-level state did not")
+	}
+
+	PrecomputedFeedback = nil
+	if Default().FeedbackTable() != nil {
+		t.Error("Default() did not follow PrecomputedFeedback")
+	}
+
+	AllowedGuesses = mustNewWords("apple")
+	if !slices.Equal(Default().Guesses(), AllowedGuesses) {
+		t.Error("Default() did not follow AllowedGuesses")
+	}
+}
+
+func TestDefault_Concurrent(t *testing.T) {
+	oldTable := PrecomputedFeedback
+	defer func() { PrecomputedFeedback = oldTable }()
+	PrecomputedFeedback = nil
+
+	// Every caller sees the one engine rebuilt for the changed table
+	engines := make([]*Engine, 8)
+	var wg sync.WaitGroup
+	for i := range engines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				engines[i] = Default()
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i, e := range engines {
+		if e != engines[0] || e.FeedbackTable() != nil {
+			t.Errorf("goroutine %d got engine %p with table %p, want %p with none", i, e, e.FeedbackTable(), engines[0])
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package wordlegameengine
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"sync"
+)
+
+const feedbackTableMagic = "WFBT"
+
+// Tables are written at version 1, with a byte per code, when every code fits in one, as it does for
+// words of up to 5 letters. Longer words need version 2, with two bytes per code
+const (
+	feedbackTableVersionNarrow = 1
+	feedbackTableVersionWide   = 2
+)
+
+// maxFeedbackTableWords bounds each dimension of a table read from a file, far above any real wordlist
+const maxFeedbackTableWords = 1 << 20
+
+var ErrWordlistMismatch = errors.New("built against different wordlists")
+
+// FeedbackTable holds the encoded feedback (see Feedback.Encode) of every word in AllowedGuesses
+// against every word in AllowedSolutions, so that CheckGuess can be replaced by a lookup
+type FeedbackTable struct {
+	fingerprint   uint64
+	numGuesses    int
+	numSolutions  int
+	codes         FeedbackRow // code of guessIdx*numSolutions+solutionIdx
+	guessIndex    map[Word]int
+	solutionIndex map[Word]int
+}
+
+// FeedbackRow is a run of encoded feedback from a FeedbackTable. Codes are stored in a byte each
+// when the word length allows, and in a uint16 otherwise
+type FeedbackRow struct {
+	narrow []uint8
+	wide   []uint16
+}
+
+// newFeedbackRow allocates n codes for words of the given length
+func newFeedbackRow(n, length int) FeedbackRow {
+	if NumFeedbacks(length) <= 1<<8 {
+		return FeedbackRow{narrow: make([]uint8, n)}
+	}
+	return FeedbackRow{wide: make([]uint16, n)}
+}
+
+// At returns the code at index i
+func (r FeedbackRow) At(i int) uint16 {
+	if r.wide != nil {
+		return r.wide[i]
+	}
+	return uint16(r.narrow[i])
+}
+
+// Len returns the number of codes in the row
+func (r FeedbackRow) Len() int {
+	if r.wide != nil {
+		return len(r.wide)
+	}
+	return len(r.narrow)
+}
+
+func (r FeedbackRow) set(i int, code uint16) {
+	if r.wide != nil {
+		r.wide[i] = code
+	} else {
+		r.narrow[i] = uint8(code)
+	}
+}
+
+func (r FeedbackRow) slice(from, to int) FeedbackRow {
+	if r.wide != nil {
+		return FeedbackRow{wide: r.wide[from:to]}
+	}
+	return FeedbackRow{narrow: r.narrow[from:to]}
+}
+
+// PrecomputedFeedback is the optional table used by games and solvers. When nil, feedback is
+// computed with CheckGuess
+var PrecomputedFeedback *FeedbackTable
+
+// InitFeedbackTable builds the table for the loaded wordlists and makes it the global table
+func InitFeedbackTable() {
+	PrecomputedFeedback = NewFeedbackTable(AllowedGuesses, AllowedSolutions)
+}
+
+// UseFeedbackTable makes t the global table, after checking it was built for the loaded wordlists
+func UseFeedbackTable(t *FeedbackTable) error {
+	if err := t.checkWordlists(AllowedGuesses, AllowedSolutions); err != nil {
+		return err
+	}
+	PrecomputedFeedback = t
+	return nil
+}
+
+// checkWordlists verifies the table was built for the given wordlists, and builds its indexes if
+// it was read from a file
+func (t *FeedbackTable) checkWordlists(guesses, solutions []Word) error {
+	if t.fingerprint != wordlistFingerprint(guesses, solutions) {
+		return fmt.Errorf("feedback table: %w", ErrWordlistMismatch)
+	}
+	if t.guessIndex == nil {
+		t.buildIndexes(guesses, solutions)
+	}
+	return nil
+}
+
+// NewFeedbackTable computes the feedback of every guess against every solution
+func NewFeedbackTable(guesses, solutions []Word) *FeedbackTable {
+	length := DefaultWordLength
+	if len(guesses) > 0 {
+		length = guesses[0].Len()
+	}
+	t := &FeedbackTable{
+		fingerprint:  wordlistFingerprint(guesses, solutions),
+		numGuesses:   len(guesses),
+		numSolutions: len(solutions),
+		codes:        newFeedbackRow(len(guesses)*len(solutions), length),
+	}
+
+	// Each worker fills the rows of every numWorkers'th guess
+	var wg sync.WaitGroup
+	for w := 0; w < numWorkers; w++ {
+		wg.Add(1)
+		go func(start int) {
+			defer wg.Done()
+			for g := start; g < len(guesses); g += numWorkers {
+				row := t.Row(g)
+				for s := range solutions {
+					solution := Solution(solutions[s])
+					row.set(s, solution.CheckGuess(guesses[g]).Encode())
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	t.buildIndexes(guesses, solutions)
+	return t
+}
+
+func (t *FeedbackTable) buildIndexes(guesses, solutions []Word) {
+	t.guessIndex = make(map[Word]int, len(guesses))
+	for i, w := range guesses {
+		t.guessIndex[w] = i
+	}
+	t.solutionIndex = make(map[Word]int, len(solutions))
+	for i, w := range solutions {
+		t.solutionIndex[w] = i
+	}
+}
+
+// Lookup returns the encoded feedback of the guess at guessIdx against the solution at solutionIdx
+func (t *FeedbackTable) Lookup(guessIdx, solutionIdx int) uint16 {
+	return t.codes.At(guessIdx*t.numSolutions + solutionIdx)
+}
+
+// Row returns the encoded feedback of the guess at guessIdx against every solution
+func (t *FeedbackTable) Row(guessIdx int) FeedbackRow {
+	return t.codes.slice(guessIdx*t.numSolutions, (guessIdx+1)*t.numSolutions)
+}
+
+// GuessIndex returns the position of w in the guess list the table was built from
+func (t *FeedbackTable) GuessIndex(w Word) (int, bool) {
+	idx, ok := t.guessIndex[w]
+	return idx, ok
+}
+
+// SolutionIndex returns the position of w in the solution list the table was built from
+func (t *FeedbackTable) SolutionIndex(w Word) (int, bool) {
+	idx, ok := t.solutionIndex[w]
+	return idx, ok
+}
+
+// WriteTo writes the table in a versioned binary format: magic, version, wordlist fingerprint,
+// dimensions, then a code per guess/solution pair, as a byte at version 1 or a little-endian
+// uint16 at version 2
+func (t *FeedbackTable) WriteTo(w io.Writer) (int64, error) {
+	version := uint16(feedbackTableVersionNarrow)
+	if t.codes.wide != nil {
+		version = feedbackTableVersionWide
+	}
+	header := make([]byte, 0, 22)
+	header = append(header, feedbackTableMagic...)
+	header = binary.LittleEndian.AppendUint16(header, version)
+	header = binary.LittleEndian.AppendUint64(header, t.fingerprint)
+	header = binary.LittleEndian.AppendUint32(header, uint32(t.numGuesses))
+	header = binary.LittleEndian.AppendUint32(header, uint32(t.numSolutions))
+
+	n, err := w.Write(header)
+	if err != nil {
+		return int64(n), err
+	}
+	if t.codes.wide == nil {
+		m, err := w.Write(t.codes.narrow)
+		return int64(n + m), err
+	}
+	if err := binary.Write(w, binary.LittleEndian, t.codes.wide); err != nil {
+		return int64(n), err
+	}
+	return int64(n + 2*len(t.codes.wide)), nil
+}
+
+// ReadFeedbackTable reads a table written by WriteTo. Its indexes are built by UseFeedbackTable
+func ReadFeedbackTable(r io.Reader) (*FeedbackTable, error) {
+	header := make([]byte, 22)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("reading feedback table header: %w", err)
+	}
+	if string(header[:4]) != feedbackTableMagic {
+		return nil, fmt.Errorf("not a feedback table file")
+	}
+	codeSize := 0
+	switch version := binary.LittleEndian.Uint16(header[4:6]); version {
+	case feedbackTableVersionNarrow:
+		codeSize = 1
+	case feedbackTableVersionWide:
+		codeSize = 2
+	default:
+		return nil, fmt.Errorf("unsupported feedback table version %d", version)
+	}
+
+	t := &FeedbackTable{
+		fingerprint:  binary.LittleEndian.Uint64(header[6:14]),
+		numGuesses:   int(binary.LittleEndian.Uint32(header[14:18])),
+		numSolutions: int(binary.LittleEndian.Uint32(header[18:22])),
+	}
+	if t.numGuesses > maxFeedbackTableWords || t.numSolutions > maxFeedbackTableWords {
+		return nil, fmt.Errorf("feedback table of %d guesses by %d solutions: too large", t.numGuesses, t.numSolutions)
+	}
+
+	// The codes are read before they are allocated, so a corrupt header cannot cause an allocation
+	// larger than the file
+	size := int64(codeSize) * int64(t.numGuesses) * int64(t.numSolutions)
+	data, err := io.ReadAll(io.LimitReader(r, size))
+	if err != nil {
+		return nil, fmt.Errorf("reading feedback table: %w", err)
+	}
+	if int64(len(data)) != size {
+		return nil, fmt.Errorf("reading feedback table: %w", io.ErrUnexpectedEOF)
+	}
+	if codeSize == 1 {
+		t.codes = FeedbackRow{narrow: data}
+		return t, nil
+	}
+	t.codes = FeedbackRow{wide: make([]uint16, len(data)/2)}
+	for i := range t.codes.wide {
+		t.codes.wide[i] = binary.LittleEndian.Uint16(data[2*i:])
+	}
+	return t, nil
+}
+
+// Save writes the table to a file
+func (t *FeedbackTable) Save(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(file)
+	if _, err := t.WriteTo(w); err != nil {
+		file.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// LoadFeedbackTable reads a table from a file written by Save
+func LoadFeedbackTable(path string) (*FeedbackTable, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ReadFeedbackTable(bufio.NewReader(file))
+}
+
+// wordlistFingerprint identifies a pair of guess and solution lists
+func wordlistFingerprint(guesses, solutions []Word) uint64 {
+	h := fnv.New64a()
+	for _, list := range [][]Word{guesses, solutions} {
+		for _, w := range list {
+			h.Write(w[:])
+		}
+		h.Write([]byte{'|'})
+	}
+	return h.Sum64()
+}
@@ -0,0 +1,163 @@
+package wordlegameengine
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestNewFeedbackTable(t *testing.T) {
+	guesses := AllowedGuesses[:200]
+	solutions := AllowedSolutions
+	table := NewFeedbackTable(guesses, solutions)
+
+	for g, guess := range guesses {
+		for s, word := range solutions {
+			solution := Solution(word)
+			want := solution.CheckGuess(guess).Encode()
+			if got := table.Lookup(g, s); got != want {
+				t.Fatalf("Lookup(%q, %q) = %d, want %d", guess.String(), word.String(), got, want)
+			}
+		}
+	}
+}
+
+func TestFeedbackTable_Indexes(t *testing.T) {
+	table := NewFeedbackTable(AllowedGuesses[:10], AllowedSolutions[:10])
+
+	if idx, ok := table.GuessIndex(AllowedGuesses[3]); !ok || idx != 3 {
+		t.Errorf("GuessIndex(%q) = %d, %v, want 3, true", AllowedGuesses[3].String(), idx, ok)
+	}
+	if idx, ok := table.SolutionIndex(AllowedSolutions[7]); !ok || idx != 7 {
+		t.Errorf("SolutionIndex(%q) = %d, %v, want 7, true", AllowedSolutions[7].String(), idx, ok)
+	}
+	if _, ok := table.GuessIndex(mustNewWord("zzzzz")); ok {
+		t.Error("GuessIndex(\"zzzzz\") found a word outside the table")
+	}
+}
+
+func TestFeedbackTable_SaveAndLoad(t *testing.T) {
+	table := NewFeedbackTable(AllowedGuesses[:50], AllowedSolutions[:40])
+	path := filepath.Join(t.TempDir(), "feedback.bin")
+
+	if err := table.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded, err := LoadFeedbackTable(path)
+	if err != nil {
+		t.Fatalf("LoadFeedbackTable() error = %v", err)
+	}
+
+	if loaded.fingerprint != table.fingerprint {
+		t.Errorf("fingerprint = %x, want %x", loaded.fingerprint, table.fingerprint)
+	}
+	if !slices.Equal(loaded.codes.narrow, table.codes.narrow) || !slices.Equal(loaded.codes.wide, table.codes.wide) {
+		t.Error("loaded codes differ from saved codes")
+	}
+}
+
+func TestFeedbackTable_CodeWidth(t *testing.T) {
+	tests := []struct {
+		name        string
+		guesses     []Word
+		solutions   []Word
+		wantVersion byte
+		wantSize    int // Bytes after the header
+	}{
+		{"five letters", mustNewWords("crane", "slate", "moist"), mustNewWords("crane", "slate"), 1, 6},
+		{"four letters", mustNewWords("cart", "slit"), mustNewWords("cart"), 1, 2},
+		{"six letters", mustNewWords("planet", "rocket", "sphinx"), mustNewWords("planet", "rocket"), 2, 12},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := NewFeedbackTable(tt.guesses, tt.solutions)
+			var buf bytes.Buffer
+			n, err := table.WriteTo(&buf)
+			if err != nil {
+				t.Fatalf("WriteTo() error = %v", err)
+			}
+			if n != int64(buf.Len()) || buf.Bytes()[4] != tt.wantVersion || buf.Len()-22 != tt.wantSize {
+				t.Errorf("WriteTo() wrote %d bytes (reported %d) at version %d, want %d code bytes at version %d",
+					buf.Len(), n, buf.Bytes()[4], tt.wantSize, tt.wantVersion)
+			}
+
+			loaded, err := ReadFeedbackTable(&buf)
+			if err != nil {
+				t.Fatalf("ReadFeedbackTable() error = %v", err)
+			}
+			for g, guess := range tt.guesses {
+				for s, word := range tt.solutions {
+					solution := Solution(word)
+					if got, want := loaded.Lookup(g, s), solution.CheckGuess(guess).Encode(); got != want {
+						t.Errorf("Lookup(%q, %q) = %d, want %d", guess.String(), word.String(), got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestReadFeedbackTable_Invalid(t *testing.T) {
+	var buf bytes.Buffer
+	NewFeedbackTable(AllowedGuesses[:5], AllowedSolutions[:5]).WriteTo(&buf)
+	valid := buf.Bytes()
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"bad magic", append([]byte("XXXX"), valid[4:]...)},
+		{"bad version", append(append([]byte{}, valid[:4]...), append([]byte{9, 0}, valid[6:]...)...)},
+		{"truncated", valid[:len(valid)-1]},
+		{"huge dimensions", append(append([]byte{}, valid[:14]...), 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff)},
+		{"large dimensions, truncated", append(append([]byte{}, valid[:14]...), 0, 0, 0x10, 0, 0, 0, 0x10, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ReadFeedbackTable(bytes.NewReader(tt.data)); err == nil {
+				t.Error("ReadFeedbackTable() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestUseFeedbackTable_WordlistMismatch(t *testing.T) {
+	oldTable := PrecomputedFeedback
+	defer func() { PrecomputedFeedback = oldTable }()
+
+	table := NewFeedbackTable(AllowedGuesses[:5], AllowedSolutions[:5])
+	if err := UseFeedbackTable(table); !errors.Is(err, ErrWordlistMismatch) {
+		t.Errorf("UseFeedbackTable() error = %v, want ErrWordlistMismatch", err)
+	}
+	if PrecomputedFeedback != oldTable {
+		t.Error("UseFeedbackTable() replaced the global table despite the mismatch")
+	}
+}
+
+func TestGame_ShortlistWithFeedbackTable(t *testing.T) {
+	oldTable := PrecomputedFeedback
+	defer func() { PrecomputedFeedback = oldTable }()
+
+	turns := []string{"slate", "trace"}
+
+	PrecomputedFeedback = nil
+	withoutTable := NewGame(mustNewSolution("crane"))
+	for _, guess := range turns {
+		withoutTable.PlayGuess(mustNewWord(guess))
+	}
+
+	InitFeedbackTable()
+	withTable := NewGame(mustNewSolution("crane"))
+	for _, guess := range turns {
+		withTable.PlayGuess(mustNewWord(guess))
+	}
+
+	if withTable.ShortlistLength() != withoutTable.ShortlistLength() {
+		t.Errorf("ShortlistLength() with table = %d, without = %d", withTable.ShortlistLength(), withoutTable.ShortlistLength())
+	}
+}
@@ -0,0 +1,277 @@
+package wordlegameengine
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+const firstTurnsMagic = "WFTS"
+const firstTurnsVersion = 2
+
+// maxEncodedSolutions is the most allowed solutions shortlists can be written for, as a
+// shortlist's size and indexes are stored as uint16s
+const maxEncodedSolutions = 1<<16 - 1
+
+var ErrTooManySolutions = errors.New("too many solutions to encode shortlists")
+
+// WriteFirstTurns computes the shortlist left by every achievable feedback for each opener, and
+// writes them in a versioned binary format: magic, version, wordlist fingerprint, number of
+// solutions and of openers, then one block per opener. A block is the opener's index in
+// AllowedGuesses and its number of entries (uint16), then per feedback the encoded feedback
+// (uint16), the shortlist size and the shortlist as AllowedSolutions indexes or as a bitset,
+// whichever is smaller.
+// It returns the number of entries written
+func WriteFirstTurns(w io.Writer, openers []Word) (int, error) {
+	return Default().WriteFirstTurns(w, openers)
+}
+
+// WriteFirstTurns writes the first-turn shortlists of the openers against the engine's wordlists;
+// see the package-level WriteFirstTurns
+func (e *Engine) WriteFirstTurns(w io.Writer, openers []Word) (int, error) {
+	if err := e.checkShortlistEncoding(); err != nil {
+		return 0, err
+	}
+	guessIndex := e.guessIndexes()
+	for _, opener := range openers {
+		if _, ok := guessIndex[opener]; !ok {
+			return 0, errNotInWordlist(opener.String())
+		}
+	}
+
+	header := make([]byte, 0, 22)
+	header = append(header, firstTurnsMagic...)
+	header = binary.LittleEndian.AppendUint16(header, firstTurnsVersion)
+	header = binary.LittleEndian.AppendUint64(header, e.fingerprint)
+	header = binary.LittleEndian.AppendUint32(header, uint32(len(e.solutions)))
+	header = binary.LittleEndian.AppendUint32(header, uint32(len(openers)))
+	if _, err := w.Write(header); err != nil {
+		return 0, err
+	}
+
+	numEntries := 0
+	for _, opener := range openers {
+		masks := e.buildFeedbackMasks(opener)
+
+		block := binary.LittleEndian.AppendUint32(nil, uint32(guessIndex[opener]))
+		block = append(block, 0, 0) // Number of entries, filled in below
+		entries := 0
+		for i, code := range masks.codes {
+			block = e.appendFirstTurn(block, code, masks.masks[i])
+			entries++
+		}
+		binary.LittleEndian.PutUint16(block[4:6], uint16(entries))
+
+		if _, err := w.Write(block); err != nil {
+			return numEntries, err
+		}
+		numEntries += entries
+	}
+	return numEntries, nil
+}
+
+func (e *Engine) appendFirstTurn(buf []byte, code uint16, shortlist Shortlist) []byte {
+	buf = binary.LittleEndian.AppendUint16(buf, code)
+	return e.appendShortlist(buf, shortlist)
+}
+
+// appendShortlist encodes the shortlist size, then the shortlist as indexes into the allowed
+// solutions or as a bitset, whichever is smaller
+func (e *Engine) appendShortlist(buf []byte, shortlist Shortlist) []byte {
+	count := shortlist.Count()
+	buf = binary.LittleEndian.AppendUint16(buf, uint16(count))
+
+	numWords := (len(e.solutions) + 63) / 64
+	if useIndexList(count, numWords) {
+		for idx := range shortlist.Indexes() {
+			buf = binary.LittleEndian.AppendUint16(buf, uint16(idx))
+		}
+		return buf
+	}
+	for i := 0; i < numWords; i++ {
+		var b uint64
+		if i < len(shortlist.bits) {
+			b = shortlist.bits[i]
+		}
+		buf = binary.LittleEndian.AppendUint64(buf, b)
+	}
+	return buf
+}
+
+// checkShortlistEncoding verifies that the engine's shortlists can be written by appendShortlist
+func (e *Engine) checkShortlistEncoding() error {
+	if len(e.solutions) > maxEncodedSolutions {
+		return fmt.Errorf("%d solutions, at most %d: %w", len(e.solutions), maxEncodedSolutions, ErrTooManySolutions)
+	}
+	return nil
+}
+
+// useIndexList reports whether a shortlist of count words is stored as a list of 2-byte indexes,
+// rather than as a bitset of numWords uint64s
+func useIndexList(count, numWords int) bool {
+	return 2*count < 8*numWords
+}
+
+// ReadFirstTurns reads shortlists written by WriteFirstTurns into cache, keyed by first turn. It
+// returns the number of entries read, and ErrWordlistMismatch if they were written for other wordlists
+func ReadFirstTurns(r io.Reader, cache *ShortlistCache) (int, error) {
+	return Default().readFirstTurns(r, cache)
+}
+
+// ReadFirstTurns reads shortlists written by WriteFirstTurns into the engine's cache
+func (e *Engine) ReadFirstTurns(r io.Reader) (int, error) {
+	return e.readFirstTurns(r, e.cache)
+}
+
+func (e *Engine) readFirstTurns(r io.Reader, cache *ShortlistCache) (int, error) {
+	header := make([]byte, 22)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, fmt.Errorf("reading first turns header: %w", err)
+	}
+	if string(header[:4]) != firstTurnsMagic {
+		return 0, fmt.Errorf("not a first turns file")
+	}
+	if version := binary.LittleEndian.Uint16(header[4:6]); version != firstTurnsVersion {
+		return 0, fmt.Errorf("unsupported first turns version %d", version)
+	}
+	if binary.LittleEndian.Uint64(header[6:14]) != e.fingerprint ||
+		int(binary.LittleEndian.Uint32(header[14:18])) != len(e.solutions) {
+		return 0, fmt.Errorf("first turns: %w", ErrWordlistMismatch)
+	}
+	numOpeners := int(binary.LittleEndian.Uint32(header[18:22]))
+
+	numEntries := 0
+	for i := 0; i < numOpeners; i++ {
+		blockHeader := make([]byte, 6)
+		if _, err := io.ReadFull(r, blockHeader); err != nil {
+			return numEntries, fmt.Errorf("reading first turns opener %d: %w", i, err)
+		}
+		guessIdx := int(binary.LittleEndian.Uint32(blockHeader[0:4]))
+		if guessIdx >= len(e.guesses) {
+			return numEntries, fmt.Errorf("first turns opener %d: invalid guess index %d", i, guessIdx)
+		}
+		opener := e.guesses[guessIdx]
+
+		for j := 0; j < int(binary.LittleEndian.Uint16(blockHeader[4:6])); j++ {
+			code, shortlist, err := e.readFirstTurn(r)
+			if err != nil {
+				return numEntries, fmt.Errorf("reading first turns for %q: %w", opener.String(), err)
+			}
+			cache.Put(MakeCacheKey(opener, DecodeFeedback(code, e.wordLength)), shortlist)
+			numEntries++
+		}
+	}
+	return numEntries, nil
+}
+
+// readFirstTurn reads one entry written by appendFirstTurn
+func (e *Engine) readFirstTurn(r io.Reader) (uint16, Shortlist, error) {
+	var codeBytes [2]byte
+	if _, err := io.ReadFull(r, codeBytes[:]); err != nil {
+		return 0, Shortlist{}, err
+	}
+	code := binary.LittleEndian.Uint16(codeBytes[:])
+	if int(code) >= e.numFeedbacks {
+		return 0, Shortlist{}, fmt.Errorf("invalid feedback code %d", code)
+	}
+	shortlist, err := e.readShortlist(r)
+	return code, shortlist, err
+}
+
+// readShortlist reads a shortlist written by appendShortlist
+func (e *Engine) readShortlist(r io.Reader) (Shortlist, error) {
+	var countBytes [2]byte
+	if _, err := io.ReadFull(r, countBytes[:]); err != nil {
+		return Shortlist{}, err
+	}
+	count := int(binary.LittleEndian.Uint16(countBytes[:]))
+
+	shortlist := e.emptyShortlist()
+	numWords := len(shortlist.bits)
+
+	if useIndexList(count, numWords) {
+		data := make([]byte, 2*count)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return Shortlist{}, err
+		}
+		for k := 0; k < count; k++ {
+			idx := int(binary.LittleEndian.Uint16(data[2*k:]))
+			if idx >= len(e.solutions) {
+				return Shortlist{}, fmt.Errorf("invalid solution index %d", idx)
+			}
+			shortlist.bits[idx/64] |= 1 << (idx % 64)
+		}
+		return shortlist, e.checkShortlist(shortlist, count)
+	}
+
+	data := make([]byte, 8*numWords)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return Shortlist{}, err
+	}
+	for k := range shortlist.bits {
+		shortlist.bits[k] = binary.LittleEndian.Uint64(data[8*k:])
+	}
+	return shortlist, e.checkShortlist(shortlist, count)
+}
+
+// checkShortlist verifies that a decoded shortlist has no bits past the allowed solutions, and
+// holds the number of words it was written with, which also rules out repeated indexes
+func (e *Engine) checkShortlist(shortlist Shortlist, count int) error {
+	if tail := len(e.solutions) % 64; tail != 0 && len(shortlist.bits) > 0 {
+		if shortlist.bits[len(shortlist.bits)-1]>>tail != 0 {
+			return fmt.Errorf("shortlist has solution indexes past %d", len(e.solutions))
+		}
+	}
+	if got := shortlist.Count(); got != count {
+		return fmt.Errorf("shortlist has %d words, want %d", got, count)
+	}
+	return nil
+}
+
+// SaveFirstTurns writes the first-turn shortlists of the openers to a file
+func SaveFirstTurns(path string, openers []Word) (int, error) {
+	return Default().SaveFirstTurns(path, openers)
+}
+
+// SaveFirstTurns writes the first-turn shortlists of the openers against the engine's wordlists
+// to a file
+func (e *Engine) SaveFirstTurns(path string, openers []Word) (int, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	w := bufio.NewWriter(file)
+	n, err := e.WriteFirstTurns(w, openers)
+	if err != nil {
+		file.Close()
+		return 0, err
+	}
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return 0, err
+	}
+	return n, file.Close()
+}
+
+// LoadFirstTurns reads first-turn shortlists from a file written by SaveFirstTurns into cache
+func LoadFirstTurns(path string, cache *ShortlistCache) (int, error) {
+	return Default().loadFirstTurns(path, cache)
+}
+
+// LoadFirstTurns reads first-turn shortlists from a file written by SaveFirstTurns into the
+// engine's cache
+func (e *Engine) LoadFirstTurns(path string) (int, error) {
+	return e.loadFirstTurns(path, e.cache)
+}
+
+func (e *Engine) loadFirstTurns(path string, cache *ShortlistCache) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	return e.readFirstTurns(bufio.NewReader(file), cache)
+}
@@ -0,0 +1,179 @@
+package wordlegameengine
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestWriteFirstTurns_ReadFirstTurns(t *testing.T) {
+	openers := []Word{mustNewWord("raise"), mustNewWord("crane"), mustNewWord("fuzzy")}
+
+	var buf bytes.Buffer
+	written, err := WriteFirstTurns(&buf, openers)
+	if err != nil {
+		t.Fatalf("WriteFirstTurns() error = %v", err)
+	}
+
+	cache := NewShortlistCacheWithBudget(0)
+	read, err := ReadFirstTurns(&buf, cache)
+	if err != nil {
+		t.Fatalf("ReadFirstTurns() error = %v", err)
+	}
+	if read != written || cache.Stats().Entries != written {
+		t.Errorf("read %d entries, cached %d, want %d", read, cache.Stats().Entries, written)
+	}
+
+	// Every achievable feedback of every opener is cached with the shortlist it leaves
+	for _, opener := range openers {
+		masks := Default().buildFeedbackMasks(opener)
+		for code := range NumFeedbacks(DefaultWordLength) {
+			want := masks.get(uint16(code))
+			key := MakeCacheKey(opener, DecodeFeedback(uint16(code), DefaultWordLength))
+			got, found := cache.Get(key)
+			if found != (want.bits != nil) {
+				t.Fatalf("%q cached = %v, want %v", key, found, want.bits != nil)
+			}
+			if !slices.Equal(got.Words(), want.Words()) {
+				t.Errorf("%q shortlist = %v, want %v", key, got.Words(), want.Words())
+			}
+		}
+	}
+}
+
+func TestWriteFirstTurns_NotInWordlist(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := WriteFirstTurns(&buf, []Word{mustNewWord("zzzzz")}); !errors.Is(err, ErrNotInWordlist) {
+		t.Errorf("WriteFirstTurns() error = %v, want ErrNotInWordlist", err)
+	}
+}
+
+// mustNewTooManySolutionsEngine returns an engine with more allowed solutions than shortlists can
+// be written for
+func mustNewTooManySolutionsEngine(t *testing.T) *Engine {
+	t.Helper()
+	words := make([]Word, 0, maxEncodedSolutions+1)
+	for i := 0; len(words) <= maxEncodedSolutions; i++ {
+		var word Word
+		for j, n := 0, i; j < 4; j, n = j+1, n/26 {
+			word[j] = byte('a' + n%26)
+		}
+		words = append(words, word)
+	}
+	e, err := NewEngine(words, words)
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+	return e
+}
+
+func TestWriteFirstTurns_TooManySolutions(t *testing.T) {
+	e := mustNewTooManySolutionsEngine(t)
+	var buf bytes.Buffer
+	if _, err := e.WriteFirstTurns(&buf, e.Guesses()[:1]); !errors.Is(err, ErrTooManySolutions) {
+		t.Errorf("WriteFirstTurns() error = %v, want ErrTooManySolutions", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("WriteFirstTurns() wrote %d bytes, want none", buf.Len())
+	}
+}
+
+func TestReadFirstTurns_Invalid(t *testing.T) {
+	var buf bytes.Buffer
+	WriteFirstTurns(&buf, []Word{mustNewWord("raise")})
+	valid := buf.Bytes()
+
+	otherFingerprint := append([]byte{}, valid...)
+	otherFingerprint[6]++
+
+	// A file with a single all-grey entry for the opener, holding the given shortlist encoding
+	entry := func(count int, payload []byte) []byte {
+		data := append([]byte{}, valid[:26]...)
+		binary.LittleEndian.PutUint32(data[18:22], 1)
+		data = binary.LittleEndian.AppendUint16(data, 1) // Entries for the opener
+		data = binary.LittleEndian.AppendUint16(data, 0) // Feedback code
+		data = binary.LittleEndian.AppendUint16(data, uint16(count))
+		return append(data, payload...)
+	}
+	bitset := func(idxs ...int) []byte {
+		bits := make([]uint64, (len(AllowedSolutions)+63)/64)
+		for _, idx := range idxs {
+			bits[idx/64] |= 1 << (idx % 64)
+		}
+		var payload []byte
+		for _, b := range bits {
+			payload = binary.LittleEndian.AppendUint64(payload, b)
+		}
+		return payload
+	}
+	firstIdxs := func(n int) []int {
+		idxs := make([]int, n)
+		for i := range idxs {
+			idxs[i] = i
+		}
+		return idxs
+	}
+	padding := len(AllowedSolutions) + 1 // Within the last bitset word, past the last solution
+	const bitsetCount = 200              // Large enough to be stored as a bitset
+
+	// The crafted encodings are read when consistent
+	if _, err := ReadFirstTurns(bytes.NewReader(entry(bitsetCount, bitset(firstIdxs(bitsetCount)...))), NewShortlistCache()); err != nil {
+		t.Fatalf("ReadFirstTurns() of a crafted bitset error = %v", err)
+	}
+	if _, err := ReadFirstTurns(bytes.NewReader(entry(2, []byte{3, 0, 4, 0})), NewShortlistCache()); err != nil {
+		t.Fatalf("ReadFirstTurns() of crafted indexes error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"empty", nil, nil},
+		{"bad magic", append([]byte("XXXX"), valid[4:]...), nil},
+		{"other wordlists", otherFingerprint, ErrWordlistMismatch},
+		{"truncated", valid[:len(valid)-1], nil},
+		{"corrupt padding", entry(bitsetCount, bitset(append(firstIdxs(bitsetCount-1), padding)...)), nil},
+		{"bitset count mismatch", entry(bitsetCount, bitset(firstIdxs(bitsetCount+1)...)), nil},
+		{"repeated index", entry(2, []byte{3, 0, 3, 0}), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadFirstTurns(bytes.NewReader(tt.data), NewShortlistCache())
+			if err == nil {
+				t.Fatal("ReadFirstTurns() error = nil, want error")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("ReadFirstTurns() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSaveFirstTurns_LoadFirstTurns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "first-turns.bin")
+
+	written, err := SaveFirstTurns(path, []Word{mustNewWord("slate")})
+	if err != nil {
+		t.Fatalf("SaveFirstTurns() error = %v", err)
+	}
+	cache := NewShortlistCache()
+	read, err := LoadFirstTurns(path, cache)
+	if err != nil {
+		t.Fatalf("LoadFirstTurns() error = %v", err)
+	}
+	if read != written {
+		t.Errorf("LoadFirstTurns() = %d entries, want %d", read, written)
+	}
+
+	game := NewGame(mustNewSolution("crane"))
+	game.PlayGuess(mustNewWord("slate"))
+	shortlist, found := cache.Get(MakeCacheKey(mustNewWord("slate"), game.Feedbacks[0]))
+	if !found || shortlist.Count() != game.ShortlistLength() {
+		t.Errorf("cached shortlist = %d words (found %v), want %d", shortlist.Count(), found, game.ShortlistLength())
+	}
+}
@@ -1,128 +1,250 @@
 package wordlegameengine
 
 import (
+	"errors"
+	"fmt"
 	"math/rand/v2"
-	"sync"
+	"slices"
 )
 
+// MaxGuesses is the number of guesses allowed by the standard rules
 const MaxGuesses = 6
 const numWorkers = 16
 
+var (
+	ErrRepeatedGuess      = errors.New("guess already played")
+	ErrGameOver           = errors.New("game is already over")
+	ErrFeedbackMismatch   = errors.New("feedback does not match the solution")
+	ErrContradictoryTurns = errors.New("feedback contradicts earlier turns")
+	ErrSolutionUnknown    = errors.New("solution is unknown")
+)
+
+// TurnError reports a replayed turn whose feedback cannot be right. It unwraps to
+// ErrFeedbackMismatch or ErrContradictoryTurns
+type TurnError struct {
+	Turn     int // Index of the turn in the game's history
+	Guess    Word
+	Feedback Feedback
+	Expected Feedback // The solution's feedback, for ErrFeedbackMismatch
+	Err      error
+}
+
+func (e *TurnError) Error() string {
+	if e.Err == ErrFeedbackMismatch {
+		return fmt.Sprintf("turn %d: feedback %s for %q does not match the solution, want %s",
+			e.Turn, e.Feedback, e.Guess.String(), e.Expected)
+	}
+	return fmt.Sprintf("turn %d: feedback %s for %q contradicts earlier turns, no candidate solutions remain",
+		e.Turn, e.Feedback, e.Guess.String())
+}
+
+func (e *TurnError) Unwrap() error {
+	return e.Err
+}
+
+// Game tracks a game in progress. In assistant mode the Solution is the zero value, and the
+// game is driven only by guess/feedback pairs passed to ReplayTurn
 type Game struct {
 	Solution          Solution
 	Guesses           []Word
 	Feedbacks         []Feedback
-	SolutionShortlist []Word
+	SolutionShortlist Shortlist
+	Rules             Rules   // Checked by every turn added to the game
+	engine            *Engine // nil means the default engine, as it is when the game is played
 }
 
+// NewGame starts a game played against the default engine
 func NewGame(solution Solution) *Game {
-	return &Game{
-		Solution:          solution,
-		Guesses:           make([]Word, 0, MaxGuesses),
-		Feedbacks:         make([]Feedback, 0, MaxGuesses),
-		SolutionShortlist: append([]Word{}, AllowedSolutions...),
-	}
+	return newGame(nil, solution, FullShortlist())
 }
 
 // NewGameWithShortlist creates a game with a pre-populated solution shortlist
 // Used when loading from cache
-func NewGameWithShortlist(solution Solution, shortlist []Word) *Game {
+func NewGameWithShortlist(solution Solution, shortlist Shortlist) *Game {
+	return newGame(nil, solution, shortlist)
+}
+
+// NewAssistantGame creates a game whose solution is unknown, e.g. to help with a live game.
+// Turns are added with ReplayTurn, and SolutionShortlist holds the remaining candidates
+func NewAssistantGame() *Game {
+	return NewGame(Solution{})
+}
+
+// NewGameWithRules starts a game played against the default engine under the given rules. The
+// zero Solution gives an assistant game
+func NewGameWithRules(solution Solution, rules Rules) (*Game, error) {
+	return newGameWithRules(nil, solution, rules)
+}
+
+func newGameWithRules(engine *Engine, solution Solution, rules Rules) (*Game, error) {
+	if err := rules.Validate(); err != nil {
+		return nil, err
+	}
+	var shortlist Shortlist
+	if engine == nil {
+		shortlist = FullShortlist()
+	} else {
+		shortlist = engine.FullShortlist()
+	}
+	g := newGame(engine, solution, shortlist)
+	g.Rules = rules
+	return g, nil
+}
+
+func newGame(engine *Engine, solution Solution, shortlist Shortlist) *Game {
 	return &Game{
 		Solution:          solution,
 		Guesses:           make([]Word, 0, MaxGuesses),
 		Feedbacks:         make([]Feedback, 0, MaxGuesses),
-		SolutionShortlist: append([]Word{}, shortlist...), // Make a copy
+		SolutionShortlist: shortlist,
+		engine:            engine,
+	}
+}
+
+// Engine returns the engine the game was created by, whose wordlists it is played against
+func (g *Game) Engine() *Engine {
+	if g.engine == nil {
+		return Default()
 	}
+	return g.engine
+}
+
+// SolutionKnown reports whether the game has a solution, i.e. is not in assistant mode
+func (g *Game) SolutionKnown() bool {
+	return g.Solution != Solution{}
 }
 
 func NewRandomGame() *Game {
-	idx := rand.IntN(len(AllowedSolutions))
-	solution := Solution(AllowedSolutions[idx])
-	return NewGame(solution)
+	solutions := Default().Solutions()
+	return NewGame(Solution(solutions[rand.IntN(len(solutions))]))
 }
 
-func (g *Game) PlayGuess(guess Word) {
+// PlayGuess scores guess against the solution and adds the turn to the game. A guess the rules do
+// not allow is rejected with the error from ValidateGuess. It returns ErrSolutionUnknown in
+// assistant mode, where turns must be replayed instead
+func (g *Game) PlayGuess(guess Word) error {
+	if !g.SolutionKnown() {
+		return ErrSolutionUnknown
+	}
+	if err := g.checkRules(guess); err != nil {
+		return err
+	}
 	feedback := g.Solution.CheckGuess(guess)
 	g.Guesses = append(g.Guesses, guess)
 	g.Feedbacks = append(g.Feedbacks, feedback)
 	g.updateSolutionShortlist()
+	return nil
+}
+
+// ValidateGuess checks whether guess may be played as the next turn under the game's rules. The
+// returned error wraps ErrGameOver, one of the errors from Word.Validate, ErrGuessNotSolution when
+// guesses must be solutions, ErrRepeatedGuess unless repeats are allowed, or ErrHardModeViolation
+// in hard mode
+func (g *Game) ValidateGuess(guess Word) error {
+	return g.checkRules(guess)
 }
 
+// updateSolutionShortlist filters the shortlist by the newest turn. The previous shortlist
+// already satisfies every earlier turn, so this is one intersection with the turn's feedback mask
 func (g *Game) updateSolutionShortlist() {
+	last := len(g.Guesses) - 1
+	g.SolutionShortlist = g.SolutionShortlist.Intersect(g.Engine().feedbackMask(g.Guesses[last], g.Feedbacks[last]))
+}
 
-	// Update the game's solution shortlist. Do this by looping over the previous shortlist,
-	// assessing Words with game.matchesFeedback(), and keeping the remaining possibilities.
+func (g *Game) ShortlistLength() int {
+	return g.SolutionShortlist.Count()
+}
 
-	// prevShortlist should be populated, g.SolutionShortlist should be empty for now
+// ReplayTurn applies a past turn with its historical feedback. The feedback must match what the
+// solution gives for the guess (when the solution is known), and leave at least one candidate on
+// the shortlist; otherwise a *TurnError is returned and the game is left unchanged. A guess the
+// rules do not allow is rejected with the error from ValidateGuess
+func (g *Game) ReplayTurn(guess Word, feedback Feedback) error {
+	if err := g.checkTurn(guess, feedback); err != nil {
+		return err
+	}
 
 	prevShortlist := g.SolutionShortlist
-	g.SolutionShortlist = make([]Word, 0)
+	g.Guesses = append(g.Guesses, guess)
+	g.Feedbacks = append(g.Feedbacks, feedback)
+	g.updateSolutionShortlist()
 
-	if len(prevShortlist) == 0 {
-		return
+	if g.SolutionShortlist.Count() == 0 {
+		turn := len(g.Guesses) - 1
+		g.Guesses = g.Guesses[:turn]
+		g.Feedbacks = g.Feedbacks[:turn]
+		g.SolutionShortlist = prevShortlist
+		return &TurnError{Turn: turn, Guess: guess, Feedback: feedback, Err: ErrContradictoryTurns}
 	}
+	return nil
+}
 
-	// Prep channels for communicating with worker pool that checks words to see if they should be on the shortlist
-
-	prevShortlistCh := make(chan Word, len(prevShortlist))
-	newShortlistCh := make(chan Word, len(prevShortlist))
-
-	// Spin up worker pool to test candidate words for the solution shortlist
-
-	var wg sync.WaitGroup
-	for w := 0; w < numWorkers; w++ {
-		wg.Add(1)
-		go func(in <-chan Word, out chan<- Word) {
-			defer wg.Done()
-			for candidate := range in {
-				if g.matchesFeedback(candidate) {
-					out <- candidate
-				}
-			}
-		}(prevShortlistCh, newShortlistCh)
+// ReplayTurnCached is ReplayTurn, taking the filtered shortlist from cache under the key of the
+// turn history so far, or computing and caching it on a miss. Concurrent games replaying the same
+// turns share one computation
+func (g *Game) ReplayTurnCached(cache *ShortlistCache, guess Word, feedback Feedback) error {
+	if err := g.checkTurn(guess, feedback); err != nil {
+		return err
 	}
 
-	// Put the previous shortlist on the channel going into the worker pool. The workers will then assess whether the
-	// words belong on the shortlist.
+	turn := len(g.Guesses)
+	guesses := append(slices.Clone(g.Guesses), guess)
+	feedbacks := append(slices.Clone(g.Feedbacks), feedback)
 
-	for _, candidate := range prevShortlist {
-		prevShortlistCh <- candidate
+	// The shortlist depends only on the turns, not the solution, so it can be shared between games
+	prevShortlist := g.SolutionShortlist
+	engine := g.Engine()
+	shortlist, err := cache.GetOrCompute(MakeTurnsKey(guesses, feedbacks), func() (Shortlist, error) {
+		next := prevShortlist.Intersect(engine.feedbackMask(guess, feedback))
+		if next.Count() == 0 {
+			return Shortlist{}, &TurnError{Turn: turn, Guess: guess, Feedback: feedback, Err: ErrContradictoryTurns}
+		}
+		return next, nil
+	})
+	if err != nil {
+		return err
 	}
-	close(prevShortlistCh)
-
-	// Wait till the worker pool is ready using the waitgroup, then close its output channel for writing
-	wg.Wait()
-	close(newShortlistCh)
 
-	// Read all the words off the worker pool's output channel, and save to g.SolutionShortlist
-	for word := range newShortlistCh {
-		g.SolutionShortlist = append(g.SolutionShortlist, word)
-	}
+	g.Guesses = append(g.Guesses, guess)
+	g.Feedbacks = append(g.Feedbacks, feedback)
+	g.SolutionShortlist = shortlist
+	return nil
 }
 
-func (g *Game) matchesFeedback(candidate Word) bool {
-	candidateSolution := Solution(candidate)
-
-	for i, guess := range g.Guesses {
-		expectedFeedback := g.Feedbacks[i]
-		actualFeedback := candidateSolution.CheckGuess(guess)
-
-		if actualFeedback != expectedFeedback {
-			return false
-		}
+// RecordTurn appends a turn to the history without filtering the shortlist.
+// Used when the shortlist already reflects the turn, e.g. after a cache hit
+func (g *Game) RecordTurn(guess Word, feedback Feedback) error {
+	if err := g.checkTurn(guess, feedback); err != nil {
+		return err
 	}
-
-	return true
+	g.Guesses = append(g.Guesses, guess)
+	g.Feedbacks = append(g.Feedbacks, feedback)
+	return nil
 }
 
-func (g *Game) ShortlistLength() int {
-	return len(g.SolutionShortlist)
+// checkTurn verifies that a turn can be added to the history: its feedback is consistent, and the
+// rules allow its guess
+func (g *Game) checkTurn(guess Word, feedback Feedback) error {
+	if err := g.checkFeedback(guess, feedback); err != nil {
+		return err
+	}
+	return g.checkRules(guess)
 }
 
-func (g *Game) ReplayTurn(guess Word, feedback Feedback) {
-	g.Guesses = append(g.Guesses, guess)
-	g.Feedbacks = append(g.Feedbacks, feedback)
-	g.updateSolutionShortlist()
+// checkFeedback verifies that feedback has a tile per letter of guess, and is what the solution
+// gives for guess if the solution is known
+func (g *Game) checkFeedback(guess Word, feedback Feedback) error {
+	if feedback.Len() != guess.Len() {
+		return &ValidationError{Field: FieldFeedback, Value: feedback.String(), Position: -1, Length: guess.Len(), Err: ErrInvalidLength}
+	}
+	if !g.SolutionKnown() {
+		return nil
+	}
+	expected := g.Solution.CheckGuess(guess)
+	if expected != feedback {
+		return &TurnError{Turn: len(g.Guesses), Guess: guess, Feedback: feedback, Expected: expected, Err: ErrFeedbackMismatch}
+	}
+	return nil
 }
 
 func (g *Game) LastFeedback() *Feedback {
@@ -137,10 +259,65 @@ func (g *Game) Won() bool {
 	if feedback == nil {
 		return false
 	}
-	for i := 0; i < WordLength; i++ {
-		if feedback[i] != Green {
-			return false
+	return feedback.AllGreen()
+}
+
+// Lost reports whether all guesses allowed by the rules have been used without finding the solution
+func (g *Game) Lost() bool {
+	limit := g.Rules.guessLimit()
+	return !g.Won() && limit > 0 && len(g.Guesses) >= limit
+}
+
+// GameStatus describes the state of a game after its latest turn
+type GameStatus int8
+
+const (
+	StatusOngoing GameStatus = iota
+	StatusWon
+	StatusLost
+	// StatusAlreadyFinished means turns were played after the game had been won or lost
+	StatusAlreadyFinished
+)
+
+func (s GameStatus) String() string {
+	switch s {
+	case StatusWon:
+		return "won"
+	case StatusLost:
+		return "lost"
+	case StatusAlreadyFinished:
+		return "already_finished"
+	default:
+		return "ongoing"
+	}
+}
+
+// Finished reports whether the status is terminal, i.e. no more guesses may be played
+func (s GameStatus) Finished() bool {
+	return s != StatusOngoing
+}
+
+func (g *Game) Status() GameStatus {
+	end := g.finalTurn()
+	switch {
+	case end == -1:
+		return StatusOngoing
+	case end < len(g.Guesses)-1:
+		return StatusAlreadyFinished
+	case g.Won():
+		return StatusWon
+	default:
+		return StatusLost
+	}
+}
+
+// finalTurn returns the index of the turn that ended the game, or -1 if the game is still going
+func (g *Game) finalTurn() int {
+	limit := g.Rules.guessLimit()
+	for i, feedback := range g.Feedbacks {
+		if feedback.AllGreen() || i == limit-1 {
+			return i
 		}
 	}
-	return true
+	return -1
 }
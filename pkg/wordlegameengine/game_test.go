@@ -1,8 +1,10 @@
 package wordlegameengine
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"testing"
 )
 
@@ -35,6 +37,25 @@ func TestNewGame(t *testing.T) {
 	}
 }
 
+func TestNewGameWithShortlist(t *testing.T) {
+	shortlist := NewShortlist([]Word{mustNewWord("stare"), mustNewWord("scare"), mustNewWord("spare"), mustNewWord("share")})
+	game := NewGameWithShortlist(mustNewSolution("spare"), shortlist)
+
+	want := []Word{mustNewWord("scare"), mustNewWord("share"), mustNewWord("spare"), mustNewWord("stare")}
+	if got := game.SolutionShortlist.Words(); !slices.Equal(got, want) {
+		t.Errorf("NewGameWithShortlist().SolutionShortlist = %v, want %v", got, want)
+	}
+
+	// Filtering leaves the shortlist it started from unchanged
+	game.ReplayTurn(mustNewWord("chant"), Feedback{Grey, Grey, Green, Grey, Grey})
+	if got := game.SolutionShortlist.Words(); !slices.Equal(got, []Word{mustNewWord("spare")}) {
+		t.Errorf("SolutionShortlist after ReplayTurn = %v, want [spare]", got)
+	}
+	if shortlist.Count() != 4 {
+		t.Errorf("ReplayTurn() modified the shortlist the game was created with")
+	}
+}
+
 func TestGame_PlayGuess(t *testing.T) {
 	solution := mustNewSolution("crane")
 	game := NewGame(solution)
@@ -174,19 +195,197 @@ func TestGame_Won(t *testing.T) {
 	})
 }
 
+func TestGame_Lost(t *testing.T) {
+	t.Run("fewer than MaxGuesses", func(t *testing.T) {
+		game := NewGame(mustNewSolution("crane"))
+		game.PlayGuess(mustNewWord("slate"))
+
+		if game.Lost() {
+			t.Error("Lost() = true, want false with guesses remaining")
+		}
+	})
+
+	t.Run("MaxGuesses incorrect guesses", func(t *testing.T) {
+		game := NewGame(mustNewSolution("crane"))
+		for _, g := range []string{"slate", "trace", "brace", "grace", "space", "place"} {
+			game.PlayGuess(mustNewWord(g))
+		}
+
+		if !game.Lost() {
+			t.Error("Lost() = false, want true after MaxGuesses incorrect guesses")
+		}
+	})
+
+	t.Run("won on last guess", func(t *testing.T) {
+		game := NewGame(mustNewSolution("crane"))
+		for _, g := range []string{"slate", "trace", "brace", "grace", "space", "crane"} {
+			game.PlayGuess(mustNewWord(g))
+		}
+
+		if game.Lost() {
+			t.Error("Lost() = true, want false when the last guess wins")
+		}
+	})
+}
+
+func TestGame_Status(t *testing.T) {
+	tests := []struct {
+		name    string
+		guesses []string
+		want    GameStatus
+	}{
+		{"no guesses", nil, StatusOngoing},
+		{"incorrect guess", []string{"slate"}, StatusOngoing},
+		{"correct guess", []string{"slate", "crane"}, StatusWon},
+		{"won on last guess", []string{"slate", "trace", "brace", "grace", "space", "crane"}, StatusWon},
+		{"out of guesses", []string{"slate", "trace", "brace", "grace", "space", "place"}, StatusLost},
+		{"guess after win", []string{"crane", "slate"}, StatusAlreadyFinished},
+		{"guess after loss", []string{"slate", "trace", "brace", "grace", "space", "place", "crane"}, StatusAlreadyFinished},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := NewGame(mustNewSolution("crane"))
+			// Turns are added directly, as PlayGuess rejects guesses once the game is over
+			for _, g := range tt.guesses {
+				guess := mustNewWord(g)
+				game.Guesses = append(game.Guesses, guess)
+				game.Feedbacks = append(game.Feedbacks, game.Solution.CheckGuess(guess))
+			}
+			if got := game.Status(); got != tt.want {
+				t.Errorf("Status() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGameStatus_String(t *testing.T) {
+	tests := []struct {
+		status GameStatus
+		want   string
+	}{
+		{StatusOngoing, "ongoing"},
+		{StatusWon, "won"},
+		{StatusLost, "lost"},
+		{StatusAlreadyFinished, "already_finished"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.status.String(); got != tt.want {
+			t.Errorf("GameStatus(%d).String() = %q, want %q", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestGame_RecordTurn(t *testing.T) {
+	game := NewGameWithShortlist(mustNewSolution("crane"), NewShortlist([]Word{mustNewWord("crane")}))
+	game.RecordTurn(mustNewWord("slate"), Feedback{Grey, Grey, Green, Grey, Green})
+
+	if len(game.Guesses) != 1 || len(game.Feedbacks) != 1 {
+		t.Fatalf("after RecordTurn, got %d guesses and %d feedbacks, want 1 and 1", len(game.Guesses), len(game.Feedbacks))
+	}
+	if game.ShortlistLength() != 1 {
+		t.Errorf("RecordTurn should not filter the shortlist: length = %d, want 1", game.ShortlistLength())
+	}
+}
+
+func TestGame_ValidateGuess(t *testing.T) {
+	tests := []struct {
+		name    string
+		played  []string
+		guess   Word
+		wantErr error
+	}{
+		{"valid first guess", nil, mustNewWord("slate"), nil},
+		{"valid second guess", []string{"slate"}, mustNewWord("trace"), nil},
+		{"not in wordlist", nil, mustNewWord("abcde"), ErrNotInWordlist},
+		{"invalid characters", nil, Word{'H', 'e', 'l', 'l', 'o'}, ErrInvalidCharacter},
+		{"repeated guess", []string{"slate"}, mustNewWord("slate"), ErrRepeatedGuess},
+		{"game won", []string{"crane"}, mustNewWord("slate"), ErrGameOver},
+		{"game lost", []string{"slate", "trace", "brace", "grace", "space", "place"}, mustNewWord("crane"), ErrGameOver},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := NewGame(mustNewSolution("crane"))
+			for _, g := range tt.played {
+				game.PlayGuess(mustNewWord(g))
+			}
+			err := game.ValidateGuess(tt.guess)
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("ValidateGuess(%q) error = %v, want nil", tt.guess, err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateGuess(%q) error = %v, want %v", tt.guess, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGame_ValidateGuess_HardMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		hardMode bool
+		guess    string
+		wantErr  error
+	}{
+		{"hint reused", true, "trace", nil},
+		{"green moved", true, "arose", ErrHardModeViolation},
+		{"hints ignored", true, "crony", ErrHardModeViolation},
+		{"hints ignored outside hard mode", false, "crony", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := NewGame(mustNewSolution("crane"))
+			game.Rules.HardMode = tt.hardMode
+			game.PlayGuess(mustNewWord("slate")) // --G-G
+
+			err := game.ValidateGuess(mustNewWord(tt.guess))
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("ValidateGuess(%q) error = %v, want nil", tt.guess, err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateGuess(%q) error = %v, want %v", tt.guess, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGame_ReplayTurn_HardMode(t *testing.T) {
+	game := NewAssistantGame()
+	game.Rules.HardMode = true
+	if err := game.ReplayTurn(mustNewWord("slate"), Feedback{Grey, Yellow, Grey, Grey, Grey}); err != nil {
+		t.Fatalf("ReplayTurn() error = %v", err)
+	}
+	before := game.ShortlistLength()
+
+	err := game.ReplayTurn(mustNewWord("crane"), allGrey)
+	var violation *HardModeViolation
+	if !errors.As(err, &violation) {
+		t.Fatalf("ReplayTurn() error = %v, want *HardModeViolation", err)
+	}
+	if violation.Turn != 0 || violation.Letter != 'l' || violation.Position != -1 {
+		t.Errorf("violation = %+v, want the yellow 'l' of turn 0", *violation)
+	}
+	if len(game.Guesses) != 1 || game.ShortlistLength() != before {
+		t.Error("ReplayTurn() changed the game after a hard mode violation")
+	}
+}
+
 func TestGame_SolutionShortlist_Smoke(t *testing.T) {
 	// Set up a game with solution "spare" and a pre-filtered shortlist
 	game := &Game{
 		Solution:  mustNewSolution("spare"),
 		Guesses:   []Word{mustNewWord("scare")},
 		Feedbacks: []Feedback{{Green, Grey, Green, Green, Green}},
-		SolutionShortlist: []Word{
+		SolutionShortlist: NewShortlist([]Word{
 			mustNewWord("scare"),
 			mustNewWord("share"),
 			mustNewWord("snare"),
 			mustNewWord("spare"),
 			mustNewWord("stare"),
-		},
+		}),
 	}
 
 	// Play "chant" - should get grey-grey-green-grey-grey against "spare"
@@ -199,11 +398,44 @@ func TestGame_SolutionShortlist_Smoke(t *testing.T) {
 
 	// Only "spare" should remain in shortlist - it's the only word where
 	// "chant" produces grey-grey-green-grey-grey
-	if len(game.SolutionShortlist) != 1 {
-		t.Errorf("SolutionShortlist length = %d, want 1", len(game.SolutionShortlist))
+	words := game.SolutionShortlist.Words()
+	if len(words) != 1 {
+		t.Errorf("SolutionShortlist length = %d, want 1", len(words))
+	}
+	if len(words) > 0 && words[0] != mustNewWord("spare") {
+		t.Errorf("SolutionShortlist[0] = %v, want 'spare'", words[0])
+	}
+}
+
+func TestGame_SolutionShortlist_Incremental(t *testing.T) {
+	game := NewGame(mustNewSolution("crane"))
+	for _, guess := range []Word{mustNewWord("slate"), mustNewWord("briny")} {
+		if err := game.PlayGuess(guess); err != nil {
+			t.Fatalf("PlayGuess(%q) error = %v", guess.String(), err)
+		}
 	}
-	if len(game.SolutionShortlist) > 0 && game.SolutionShortlist[0] != mustNewWord("spare") {
-		t.Errorf("SolutionShortlist[0] = %v, want 'spare'", game.SolutionShortlist[0])
+
+	// Compare with checking every solution against every turn
+	var want []Word
+	for _, candidate := range AllowedSolutions {
+		candidateSolution := Solution(candidate)
+		keep := true
+		for i, guess := range game.Guesses {
+			if candidateSolution.CheckGuess(guess) != game.Feedbacks[i] {
+				keep = false
+			}
+		}
+		if keep {
+			want = append(want, candidate)
+		}
+	}
+
+	got := game.SolutionShortlist.Words()
+	if !slices.Equal(got, want) {
+		t.Errorf("SolutionShortlist = %v, want %v", got, want)
+	}
+	if !slices.IsSortedFunc(got, Word.Compare) {
+		t.Error("SolutionShortlist is not in alphabetical order")
 	}
 }
 
@@ -224,13 +456,14 @@ func TestGame_ShortlistLength(t *testing.T) {
 		t.Errorf("ShortlistLength() after guess = %d, should be <= %d", afterLength, initialLength)
 	}
 
-	// Test with a guess that eliminates many words
+	// A guess that is not allowed is not played, so leaves the shortlist as it was
 	game2 := NewGame(solution)
 	initialLength2 := game2.ShortlistLength()
-	game2.PlayGuess(mustNewWord("aaaaa"))
-	afterLength2 := game2.ShortlistLength()
-	if afterLength2 >= initialLength2 {
-		t.Errorf("ShortlistLength() after invalid guess = %d, should be < %d", afterLength2, initialLength2)
+	if err := game2.PlayGuess(mustNewWord("aaaaa")); !errors.Is(err, ErrNotInWordlist) {
+		t.Errorf("PlayGuess() error = %v, want ErrNotInWordlist", err)
+	}
+	if afterLength2 := game2.ShortlistLength(); afterLength2 != initialLength2 {
+		t.Errorf("ShortlistLength() after invalid guess = %d, want %d", afterLength2, initialLength2)
 	}
 }
 
@@ -297,3 +530,235 @@ func mustNewSolution(s string) Solution {
 	}
 	return sol
 }
+
+func TestGame_ReplayTurn_FeedbackMismatch(t *testing.T) {
+	game := NewGame(mustNewSolution("slate"))
+	before := game.ShortlistLength()
+
+	err := game.ReplayTurn(mustNewWord("crane"), Feedback{Green, Green, Green, Green, Green})
+	if !errors.Is(err, ErrFeedbackMismatch) {
+		t.Fatalf("ReplayTurn() error = %v, want ErrFeedbackMismatch", err)
+	}
+
+	var turnErr *TurnError
+	if !errors.As(err, &turnErr) {
+		t.Fatalf("ReplayTurn() error = %v, want *TurnError", err)
+	}
+	if turnErr.Turn != 0 {
+		t.Errorf("TurnError.Turn = %d, want 0", turnErr.Turn)
+	}
+	if want := (Feedback{Grey, Grey, Green, Grey, Green}); turnErr.Expected != want {
+		t.Errorf("TurnError.Expected = %v, want %v", turnErr.Expected, want)
+	}
+
+	if len(game.Guesses) != 0 || game.ShortlistLength() != before {
+		t.Error("ReplayTurn() should leave the game unchanged when the feedback is inconsistent")
+	}
+}
+
+func TestGame_ReplayTurn_ContradictoryTurns(t *testing.T) {
+	// The shortlist does not contain the solution, so a consistent turn can still empty it
+	game := NewGameWithShortlist(mustNewSolution("crane"), NewShortlist([]Word{mustNewWord("slate"), mustNewWord("stare")}))
+
+	err := game.ReplayTurn(mustNewWord("trace"), Feedback{Grey, Green, Green, Yellow, Green})
+	if !errors.Is(err, ErrContradictoryTurns) {
+		t.Fatalf("ReplayTurn() error = %v, want ErrContradictoryTurns", err)
+	}
+	if len(game.Guesses) != 0 {
+		t.Errorf("Guesses length = %d, want 0: turn should not be recorded", len(game.Guesses))
+	}
+	if game.ShortlistLength() != 2 {
+		t.Errorf("ShortlistLength() = %d, want 2: shortlist should be restored", game.ShortlistLength())
+	}
+}
+
+func TestGame_ReplayTurnCached(t *testing.T) {
+	cache := NewShortlistCacheWithBudget(0)
+	guesses := []Word{mustNewWord("slate"), mustNewWord("crony")}
+
+	uncached := NewGame(mustNewSolution("crane"))
+	cached := NewGame(mustNewSolution("crane"))
+	for _, guess := range guesses {
+		feedback := uncached.Solution.CheckGuess(guess)
+		if err := uncached.ReplayTurn(guess, feedback); err != nil {
+			t.Fatalf("ReplayTurn() error = %v", err)
+		}
+		if err := cached.ReplayTurnCached(cache, guess, feedback); err != nil {
+			t.Fatalf("ReplayTurnCached() error = %v", err)
+		}
+	}
+
+	if !slices.Equal(cached.SolutionShortlist.Words(), uncached.SolutionShortlist.Words()) {
+		t.Errorf("ReplayTurnCached() shortlist = %v, want %v", cached.SolutionShortlist.Words(), uncached.SolutionShortlist.Words())
+	}
+	if len(cached.Guesses) != 2 || len(cached.Feedbacks) != 2 {
+		t.Errorf("ReplayTurnCached() recorded %d guesses, %d feedbacks, want 2, 2", len(cached.Guesses), len(cached.Feedbacks))
+	}
+	if stats := cache.Stats(); stats.Entries != 2 {
+		t.Errorf("cache has %d entries, want one per turn prefix", stats.Entries)
+	}
+
+	// Another game replaying the same turns is served from cache
+	other := NewGame(mustNewSolution("crane"))
+	for i, guess := range guesses {
+		if err := other.ReplayTurnCached(cache, guess, cached.Feedbacks[i]); err != nil {
+			t.Fatalf("ReplayTurnCached() error = %v", err)
+		}
+	}
+	if stats := cache.Stats(); stats.Hits != 2 {
+		t.Errorf("cache hits = %d, want 2", stats.Hits)
+	}
+}
+
+func TestGame_ReplayTurnCached_Errors(t *testing.T) {
+	cache := NewShortlistCacheWithBudget(0)
+
+	game := NewGame(mustNewSolution("crane"))
+	if err := game.ReplayTurnCached(cache, mustNewWord("slate"), allGrey); !errors.Is(err, ErrFeedbackMismatch) {
+		t.Errorf("ReplayTurnCached() error = %v, want ErrFeedbackMismatch", err)
+	}
+
+	game = NewGameWithShortlist(mustNewSolution("crane"), NewShortlist([]Word{mustNewWord("slate"), mustNewWord("stare")}))
+	err := game.ReplayTurnCached(cache, mustNewWord("trace"), Feedback{Grey, Green, Green, Yellow, Green})
+	if !errors.Is(err, ErrContradictoryTurns) {
+		t.Errorf("ReplayTurnCached() error = %v, want ErrContradictoryTurns", err)
+	}
+	if len(game.Guesses) != 0 || game.ShortlistLength() != 2 {
+		t.Errorf("ReplayTurnCached() changed the game after an error")
+	}
+	if stats := cache.Stats(); stats.Entries != 0 {
+		t.Errorf("cache has %d entries, want 0", stats.Entries)
+	}
+}
+
+func TestGame_RecordTurn_FeedbackMismatch(t *testing.T) {
+	game := NewGameWithShortlist(mustNewSolution("crane"), NewShortlist([]Word{mustNewWord("crane")}))
+	err := game.RecordTurn(mustNewWord("slate"), allGrey)
+
+	if !errors.Is(err, ErrFeedbackMismatch) {
+		t.Errorf("RecordTurn() error = %v, want ErrFeedbackMismatch", err)
+	}
+	if len(game.Guesses) != 0 {
+		t.Errorf("RecordTurn() recorded an inconsistent turn")
+	}
+}
+
+func TestAssistantGame_ReplayTurn_FeedbackLength(t *testing.T) {
+	game := NewAssistantGame()
+	err := game.ReplayTurn(mustNewWord("slate"), Feedback{Grey, Grey, Grey, Grey})
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || !errors.Is(err, ErrInvalidLength) {
+		t.Fatalf("ReplayTurn() error = %v, want ErrInvalidLength", err)
+	}
+	if validationErr.Field != FieldFeedback || validationErr.Length != 5 {
+		t.Errorf("error field = %q, length = %d, want %q, 5", validationErr.Field, validationErr.Length, FieldFeedback)
+	}
+	if len(game.Guesses) != 0 {
+		t.Errorf("ReplayTurn() recorded a turn with feedback of the wrong length")
+	}
+}
+
+func TestNewAssistantGame(t *testing.T) {
+	game := NewAssistantGame()
+
+	if game.SolutionKnown() {
+		t.Error("SolutionKnown() = true, want false for an assistant game")
+	}
+	if game.ShortlistLength() != len(AllowedSolutions) {
+		t.Errorf("ShortlistLength() = %d, want %d", game.ShortlistLength(), len(AllowedSolutions))
+	}
+	if err := game.PlayGuess(mustNewWord("slate")); !errors.Is(err, ErrSolutionUnknown) {
+		t.Errorf("PlayGuess() error = %v, want ErrSolutionUnknown", err)
+	}
+	if len(game.Guesses) != 0 {
+		t.Errorf("PlayGuess() recorded a guess in assistant mode")
+	}
+}
+
+func TestAssistantGame_ReplayTurn(t *testing.T) {
+	game := NewAssistantGame()
+
+	// Turns from a game with solution "crane"
+	if err := game.ReplayTurn(mustNewWord("slate"), Feedback{Grey, Grey, Green, Grey, Green}); err != nil {
+		t.Fatalf("ReplayTurn() error = %v", err)
+	}
+	if err := game.ReplayTurn(mustNewWord("trace"), Feedback{Grey, Green, Green, Yellow, Green}); err != nil {
+		t.Fatalf("ReplayTurn() error = %v", err)
+	}
+
+	if !game.SolutionShortlist.Contains(mustNewWord("crane")) {
+		t.Errorf("SolutionShortlist = %v, want it to contain crane", game.SolutionShortlist.Words())
+	}
+	if game.Status() != StatusOngoing {
+		t.Errorf("Status() = %v, want ongoing", game.Status())
+	}
+
+	if err := game.ReplayTurn(mustNewWord("crane"), Feedback{Green, Green, Green, Green, Green}); err != nil {
+		t.Fatalf("ReplayTurn() error = %v", err)
+	}
+	if game.Status() != StatusWon {
+		t.Errorf("Status() = %v, want won", game.Status())
+	}
+}
+
+func TestAssistantGame_ReplayTurn_ContradictoryTurns(t *testing.T) {
+	game := NewAssistantGame()
+	if err := game.ReplayTurn(mustNewWord("slate"), allGrey); err != nil {
+		t.Fatalf("ReplayTurn() error = %v", err)
+	}
+	before := game.ShortlistLength()
+
+	// Every letter of "least" was grey in "slate", so it cannot be all green
+	err := game.ReplayTurn(mustNewWord("least"), Feedback{Green, Green, Green, Green, Green})
+	if !errors.Is(err, ErrContradictoryTurns) {
+		t.Fatalf("ReplayTurn() error = %v, want ErrContradictoryTurns", err)
+	}
+	var turnErr *TurnError
+	if !errors.As(err, &turnErr) || turnErr.Turn != 1 {
+		t.Errorf("ReplayTurn() error = %v, want *TurnError for turn 1", err)
+	}
+	if game.ShortlistLength() != before {
+		t.Errorf("ShortlistLength() = %d, want %d: shortlist should be unchanged", game.ShortlistLength(), before)
+	}
+}
+
+// benchmarkGuesses narrow the shortlist for "crane" over four turns
+var benchmarkGuesses = []string{"raise", "clout", "chafe", "crane"}
+
+func benchmarkReplay(b *testing.B, turns int) {
+	solution := mustNewSolution("crane")
+	guesses := make([]Word, turns)
+	feedbacks := make([]Feedback, turns)
+	for i := range guesses {
+		guesses[i] = mustNewWord(benchmarkGuesses[i])
+		feedbacks[i] = solution.CheckGuess(guesses[i])
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		game := NewGame(solution)
+		for t := range guesses {
+			if err := game.ReplayTurn(guesses[t], feedbacks[t]); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkGame_ReplayTurn_First(b *testing.B) {
+	benchmarkReplay(b, 1)
+}
+
+func BenchmarkGame_ReplayTurn_FourTurns(b *testing.B) {
+	benchmarkReplay(b, 4)
+}
+
+func BenchmarkGame_ReplayTurn_FourTurnsWithTable(b *testing.B) {
+	oldTable := PrecomputedFeedback
+	defer func() { PrecomputedFeedback = oldTable }()
+	InitFeedbackTable()
+	b.ResetTimer()
+
+	benchmarkReplay(b, 4)
+}
@@ -0,0 +1,66 @@
+package wordlegameengine
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrHardModeViolation = errors.New("guess ignores a revealed hint")
+
+// HardModeViolation reports a guess that breaks Wordle's hard mode rules: revealed greens must be
+// reused in place, and revealed yellows must be included. It unwraps to ErrHardModeViolation
+type HardModeViolation struct {
+	Guess    Word
+	Turn     int  // Index of the earlier turn that revealed the hint
+	Letter   byte // The letter the guess must use
+	Position int  // Where a green letter must be, or -1 for a letter that must be included
+	Count    int  // How many times the letter must be included, counting greens and yellows
+}
+
+func (e *HardModeViolation) Error() string {
+	switch {
+	case e.Position >= 0:
+		return fmt.Sprintf("%q: letter %d must be %q, revealed by turn %d",
+			e.Guess.String(), e.Position+1, e.Letter, e.Turn)
+	case e.Count > 1:
+		return fmt.Sprintf("%q must contain %q %d times, revealed by turn %d",
+			e.Guess.String(), e.Letter, e.Count, e.Turn)
+	default:
+		return fmt.Sprintf("%q must contain %q, revealed by turn %d", e.Guess.String(), e.Letter, e.Turn)
+	}
+}
+
+func (e *HardModeViolation) Unwrap() error {
+	return ErrHardModeViolation
+}
+
+// CheckHardMode checks that guess uses every hint revealed by the previous turns, returning a
+// *HardModeViolation for the first it ignores. A letter revealed more than once by a turn, as
+// greens or yellows, must appear in the guess at least that many times
+func CheckHardMode(guess Word, guesses []Word, feedbacks []Feedback) error {
+	var guessCounts [26]int
+	for _, letter := range guess[:guess.Len()] {
+		guessCounts[letter-'a']++
+	}
+
+	for turn, previous := range guesses {
+		var required [26]int
+		for i, color := range feedbacks[turn][:feedbacks[turn].Len()] {
+			switch color {
+			case Green:
+				if guess[i] != previous[i] {
+					return &HardModeViolation{Guess: guess, Turn: turn, Letter: previous[i], Position: i, Count: 1}
+				}
+				required[previous[i]-'a']++
+			case Yellow:
+				required[previous[i]-'a']++
+			}
+		}
+		for letter, count := range required {
+			if guessCounts[letter] < count {
+				return &HardModeViolation{Guess: guess, Turn: turn, Letter: byte('a' + letter), Position: -1, Count: count}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package wordlegameengine
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckHardMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous string
+		feedback string
+		guess    string
+		want     *HardModeViolation // nil if the guess is allowed
+	}{
+		{"no hints", "slate", "-----", "crony", nil},
+		{"green reused in place", "slate", "--G--", "crane", nil},
+		{"green moved", "slate", "--G--", "about", &HardModeViolation{Letter: 'a', Position: 2, Count: 1}},
+		{"yellow included", "slate", "-Y---", "cloud", nil},
+		{"yellow missing", "slate", "-Y---", "crane", &HardModeViolation{Letter: 'l', Position: -1, Count: 1}},
+		{"duplicate yellows need two copies", "geese", "-YY--", "sheep", nil},
+		{"duplicate yellows with one copy", "geese", "-YY--", "crane", &HardModeViolation{Letter: 'e', Position: -1, Count: 2}},
+		{"green and yellow of the same letter", "eerie", "GY---", "elder", nil},
+		{"green and yellow of the same letter, one copy", "eerie", "GY---", "eclat", &HardModeViolation{Letter: 'e', Position: -1, Count: 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			feedback, err := ParseFeedback(tt.feedback)
+			if err != nil {
+				t.Fatal(err)
+			}
+			guess := mustNewWord(tt.guess)
+			err = CheckHardMode(guess, []Word{mustNewWord(tt.previous)}, []Feedback{feedback})
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("CheckHardMode(%q after %q %s) error = %v, want nil", tt.guess, tt.previous, tt.feedback, err)
+				}
+				return
+			}
+
+			var violation *HardModeViolation
+			if !errors.As(err, &violation) || !errors.Is(err, ErrHardModeViolation) {
+				t.Fatalf("CheckHardMode(%q after %q %s) error = %v, want *HardModeViolation", tt.guess, tt.previous, tt.feedback, err)
+			}
+			tt.want.Guess = guess
+			if *violation != *tt.want {
+				t.Errorf("violation = %+v, want %+v", *violation, *tt.want)
+			}
+		})
+	}
+}
+
+func TestHardModeViolation_Error(t *testing.T) {
+	tests := []struct {
+		violation HardModeViolation
+		want      string
+	}{
+		{HardModeViolation{Guess: mustNewWord("about"), Turn: 0, Letter: 'a', Position: 2, Count: 1}, `"about": letter 3 must be 'a', revealed by turn 0`},
+		{HardModeViolation{Guess: mustNewWord("crane"), Turn: 1, Letter: 'l', Position: -1, Count: 1}, `"crane" must contain 'l', revealed by turn 1`},
+		{HardModeViolation{Guess: mustNewWord("crane"), Turn: 0, Letter: 'e', Position: -1, Count: 2}, `"crane" must contain 'e' 2 times, revealed by turn 0`},
+	}
+
+	for _, tt := range tests {
+		if got := tt.violation.Error(); got != tt.want {
+			t.Errorf("Error() = %s, want %s", got, tt.want)
+		}
+	}
+}
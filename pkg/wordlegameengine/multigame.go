@@ -0,0 +1,176 @@
+package wordlegameengine
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+)
+
+// Board counts of the common multi-board variants
+const (
+	DordleBoards   = 2
+	QuordleBoards  = 4
+	OctordleBoards = 8
+	MaxBoards      = 32
+)
+
+var (
+	ErrInvalidBoards     = errors.New("invalid number of boards")
+	ErrInvalidMaxGuesses = errors.New("invalid maximum number of guesses")
+)
+
+// MultiGame plays each guess against several boards at once, as in Dordle, Quordle and Octordle.
+// Every board is a Game with its own solution and shortlist, and the boards share a budget of
+// MaxGuesses. A board is solved by the guess that matches its solution, and takes no turns after it
+type MultiGame struct {
+	Boards     []*Game
+	Guesses    []Word  // Every guess played, including those after some boards were solved
+	MaxGuesses int     // NoGuessLimit means no limit
+	engine     *Engine // nil means the default engine, as it is when the game is played
+}
+
+// DefaultMaxGuesses returns the guess budget of the standard variant with the given number of
+// boards: one more guess per board than Wordle's five spare turns, e.g. 7 for Dordle and 13 for
+// Octordle
+func DefaultMaxGuesses(boards int) int {
+	return boards + MaxGuesses - 1
+}
+
+// NewMultiGame starts a multi-board game played against the default engine, with a board per
+// solution. maxGuesses of 0 means DefaultMaxGuesses, and NoGuessLimit means no limit. The solutions
+// must be distinct
+func NewMultiGame(solutions []Solution, maxGuesses int) (*MultiGame, error) {
+	return newMultiGame(nil, solutions, maxGuesses)
+}
+
+// NewMultiGame starts a multi-board game with a board per distinct solution; see NewMultiGame
+func (e *Engine) NewMultiGame(solutions []Solution, maxGuesses int) (*MultiGame, error) {
+	return newMultiGame(e, solutions, maxGuesses)
+}
+
+// NewRandomMultiGame starts a multi-board game whose solutions are distinct words picked at random
+// from the allowed solutions
+func (e *Engine) NewRandomMultiGame(boards, maxGuesses int) (*MultiGame, error) {
+	if boards < 1 || boards > min(MaxBoards, len(e.solutions)) {
+		return nil, fmt.Errorf("%d boards: %w", boards, ErrInvalidBoards)
+	}
+	solutions := make([]Solution, boards)
+	for i, idx := range rand.Perm(len(e.solutions))[:boards] {
+		solutions[i] = Solution(e.solutions[idx])
+	}
+	return e.NewMultiGame(solutions, maxGuesses)
+}
+
+func newMultiGame(engine *Engine, solutions []Solution, maxGuesses int) (*MultiGame, error) {
+	if len(solutions) < 1 || len(solutions) > MaxBoards {
+		return nil, fmt.Errorf("%d boards: %w", len(solutions), ErrInvalidBoards)
+	}
+	if maxGuesses == 0 {
+		maxGuesses = DefaultMaxGuesses(len(solutions))
+	}
+	if maxGuesses < 1 && maxGuesses != NoGuessLimit {
+		return nil, fmt.Errorf("%d guesses: %w", maxGuesses, ErrInvalidMaxGuesses)
+	}
+	for i, solution := range solutions {
+		if slices.Contains(solutions[:i], solution) {
+			return nil, fmt.Errorf("board %d repeats solution %q: %w", i, solution.String(), ErrInvalidBoards)
+		}
+	}
+
+	g := &MultiGame{
+		Boards:     make([]*Game, len(solutions)),
+		Guesses:    make([]Word, 0, max(maxGuesses, 0)),
+		MaxGuesses: maxGuesses,
+		engine:     engine,
+	}
+	for i, solution := range solutions {
+		if err := g.Engine().ValidateSolution(solution); err != nil {
+			return nil, fmt.Errorf("board %d: %w", i, err)
+		}
+		g.Boards[i] = newGame(engine, solution, g.Engine().FullShortlist())
+		// Boards share the game's budget, and its guesses are checked by MultiGame.ValidateGuess
+		g.Boards[i].Rules = Rules{MaxGuesses: maxGuesses, AllowRepeats: true}
+	}
+	return g, nil
+}
+
+// Engine returns the engine the game was created by, whose wordlists it is played against
+func (g *MultiGame) Engine() *Engine {
+	if g.engine == nil {
+		return Default()
+	}
+	return g.engine
+}
+
+// ValidateGuess checks whether guess may be played as the next turn. The returned error wraps
+// ErrGameOver, ErrRepeatedGuess, or one of the errors from Word.Validate
+func (g *MultiGame) ValidateGuess(guess Word) error {
+	if g.Status().Finished() {
+		return ErrGameOver
+	}
+	if err := g.Engine().ValidateWord(guess); err != nil {
+		return err
+	}
+	for _, previous := range g.Guesses {
+		if previous == guess {
+			return &ValidationError{Field: FieldGuess, Value: guess.String(), Position: -1, Err: ErrRepeatedGuess}
+		}
+	}
+	return nil
+}
+
+// PlayGuess scores guess against every unsolved board, filtering each board's shortlist by its
+// feedback. A guess ValidateGuess rejects is not played, and its error is returned
+func (g *MultiGame) PlayGuess(guess Word) error {
+	if err := g.ValidateGuess(guess); err != nil {
+		return err
+	}
+	for i, board := range g.Boards {
+		if board.Won() {
+			continue
+		}
+		if err := board.PlayGuess(guess); err != nil {
+			return fmt.Errorf("board %d: %w", i, err)
+		}
+	}
+	g.Guesses = append(g.Guesses, guess)
+	return nil
+}
+
+// Solved reports whether board i has been solved
+func (g *MultiGame) Solved(i int) bool {
+	return g.Boards[i].Won()
+}
+
+// SolvedCount returns the number of boards solved so far
+func (g *MultiGame) SolvedCount() int {
+	solved := 0
+	for _, board := range g.Boards {
+		if board.Won() {
+			solved++
+		}
+	}
+	return solved
+}
+
+// GuessesRemaining returns the number of guesses left in the shared budget, or NoGuessLimit if
+// there is no limit
+func (g *MultiGame) GuessesRemaining() int {
+	if g.MaxGuesses == NoGuessLimit {
+		return NoGuessLimit
+	}
+	return max(g.MaxGuesses-len(g.Guesses), 0)
+}
+
+// Status is StatusWon once every board is solved, and StatusLost if the guesses run out first
+func (g *MultiGame) Status() GameStatus {
+	switch {
+	case g.SolvedCount() == len(g.Boards):
+		return StatusWon
+	case g.MaxGuesses != NoGuessLimit && len(g.Guesses) >= g.MaxGuesses:
+		return StatusLost
+	default:
+		return StatusOngoing
+	}
+}
@@ -0,0 +1,209 @@
+package wordlegameengine
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func mustNewSolutions(words ...string) []Solution {
+	solutions := make([]Solution, len(words))
+	for i, w := range words {
+		solutions[i] = mustNewSolution(w)
+	}
+	return solutions
+}
+
+func TestNewMultiGame(t *testing.T) {
+	tests := []struct {
+		name           string
+		solutions      []Solution
+		maxGuesses     int
+		wantMaxGuesses int
+		wantErr        error
+	}{
+		{"dordle", mustNewSolutions("crane", "slate"), 0, 7, nil},
+		{"quordle", mustNewSolutions("crane", "slate", "moist", "pudgy"), 0, 9, nil},
+		{"custom budget", mustNewSolutions("crane", "slate"), 3, 3, nil},
+		{"no boards", nil, 0, 0, ErrInvalidBoards},
+		{"too many boards", make([]Solution, MaxBoards+1), 0, 0, ErrInvalidBoards},
+		{"no limit", mustNewSolutions("crane", "slate"), NoGuessLimit, NoGuessLimit, nil},
+		{"negative budget", mustNewSolutions("crane"), -2, 0, ErrInvalidMaxGuesses},
+		{"repeated solution", mustNewSolutions("crane", "slate", "crane"), 0, 0, ErrInvalidBoards},
+		{"solution not allowed", mustNewSolutions("crane", "zzzzz"), 0, 0, ErrNotInWordlist},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game, err := NewMultiGame(tt.solutions, tt.maxGuesses)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewMultiGame() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(game.Boards) != len(tt.solutions) || game.MaxGuesses != tt.wantMaxGuesses {
+				t.Errorf("%d boards, %d guesses, want %d, %d", len(game.Boards), game.MaxGuesses, len(tt.solutions), tt.wantMaxGuesses)
+			}
+			for i, board := range game.Boards {
+				if board.Solution != tt.solutions[i] || board.ShortlistLength() != len(AllowedSolutions) {
+					t.Errorf("board %d = %q with %d candidates", i, board.Solution.String(), board.ShortlistLength())
+				}
+			}
+		})
+	}
+}
+
+func TestMultiGame_PlayGuess(t *testing.T) {
+	game, err := NewMultiGame(mustNewSolutions("crane", "slate"), 0)
+	if err != nil {
+		t.Fatalf("NewMultiGame() error = %v", err)
+	}
+
+	game.PlayGuess(mustNewWord("crane"))
+	if !game.Solved(0) || game.Solved(1) || game.SolvedCount() != 1 {
+		t.Errorf("solved = %v, %v, want only board 0", game.Solved(0), game.Solved(1))
+	}
+	if game.Status() != StatusOngoing || game.GuessesRemaining() != 6 {
+		t.Errorf("Status() = %v with %d guesses left, want ongoing with 6", game.Status(), game.GuessesRemaining())
+	}
+	wantSlate := mustNewSolution("slate")
+	if !game.Boards[1].SolutionShortlist.Contains(Word(wantSlate)) || game.Boards[1].ShortlistLength() == len(AllowedSolutions) {
+		t.Error("board 1 shortlist was not filtered by the guess")
+	}
+
+	// A solved board takes no more turns
+	game.PlayGuess(mustNewWord("slate"))
+	if len(game.Boards[0].Guesses) != 1 || len(game.Boards[1].Guesses) != 2 {
+		t.Errorf("boards played %d and %d guesses, want 1 and 2", len(game.Boards[0].Guesses), len(game.Boards[1].Guesses))
+	}
+	if game.Status() != StatusWon {
+		t.Errorf("Status() = %v, want won", game.Status())
+	}
+	if err := game.PlayGuess(mustNewWord("moist")); !errors.Is(err, ErrGameOver) {
+		t.Errorf("PlayGuess() after winning error = %v, want ErrGameOver", err)
+	}
+}
+
+func TestMultiGame_Lost(t *testing.T) {
+	game, err := NewMultiGame(mustNewSolutions("crane", "slate"), 2)
+	if err != nil {
+		t.Fatalf("NewMultiGame() error = %v", err)
+	}
+	for _, guess := range []string{"crane", "moist"} {
+		if err := game.ValidateGuess(mustNewWord(guess)); err != nil {
+			t.Fatalf("ValidateGuess(%q) error = %v", guess, err)
+		}
+		game.PlayGuess(mustNewWord(guess))
+	}
+	if game.Status() != StatusLost || game.GuessesRemaining() != 0 {
+		t.Errorf("Status() = %v with %d guesses left, want lost with 0", game.Status(), game.GuessesRemaining())
+	}
+	if err := game.ValidateGuess(mustNewWord("slate")); !errors.Is(err, ErrGameOver) {
+		t.Errorf("ValidateGuess() error = %v, want ErrGameOver", err)
+	}
+}
+
+func TestMultiGame_NoGuessLimit(t *testing.T) {
+	game, err := NewMultiGame(mustNewSolutions("crane", "slate"), NoGuessLimit)
+	if err != nil {
+		t.Fatalf("NewMultiGame() error = %v", err)
+	}
+	for _, guess := range mustNewWords("moist", "pudgy", "audio", "brick", "fjord", "lymph", "vexed", "whelk") {
+		if err := game.PlayGuess(guess); err != nil {
+			t.Fatalf("PlayGuess(%q) error = %v", guess.String(), err)
+		}
+	}
+	if game.Status() != StatusOngoing || game.GuessesRemaining() != NoGuessLimit {
+		t.Errorf("Status() = %v with %d guesses left, want ongoing with NoGuessLimit", game.Status(), game.GuessesRemaining())
+	}
+	for _, guess := range mustNewWords("crane", "slate") {
+		if err := game.PlayGuess(guess); err != nil {
+			t.Fatalf("PlayGuess(%q) error = %v", guess.String(), err)
+		}
+	}
+	if game.Status() != StatusWon {
+		t.Errorf("Status() = %v, want won", game.Status())
+	}
+}
+
+func TestMultiGame_ValidateGuess(t *testing.T) {
+	game, err := NewMultiGame(mustNewSolutions("crane", "slate"), 0)
+	if err != nil {
+		t.Fatalf("NewMultiGame() error = %v", err)
+	}
+	game.PlayGuess(mustNewWord("moist"))
+
+	tests := []struct {
+		guess   Word
+		wantErr error
+	}{
+		{mustNewWord("pudgy"), nil},
+		{mustNewWord("moist"), ErrRepeatedGuess},
+		{mustNewWord("abcde"), ErrNotInWordlist},
+	}
+	for _, tt := range tests {
+		if err := game.ValidateGuess(tt.guess); !errors.Is(err, tt.wantErr) {
+			t.Errorf("ValidateGuess(%q) error = %v, want %v", tt.guess.String(), err, tt.wantErr)
+		}
+	}
+}
+
+func TestMultiGame_PlayGuess_Invalid(t *testing.T) {
+	game, err := NewMultiGame(mustNewSolutions("crane", "slate"), 0)
+	if err != nil {
+		t.Fatalf("NewMultiGame() error = %v", err)
+	}
+	if err := game.PlayGuess(mustNewWord("moist")); err != nil {
+		t.Fatalf("PlayGuess() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		guess   Word
+		wantErr error
+	}{
+		{"not in wordlist", mustNewWord("zzzzz"), ErrNotInWordlist},
+		{"wrong length", mustNewWord("crates"), ErrInvalidLength},
+		{"repeat", mustNewWord("moist"), ErrRepeatedGuess},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := game.PlayGuess(tt.guess); !errors.Is(err, tt.wantErr) {
+				t.Errorf("PlayGuess(%q) error = %v, want %v", tt.guess.String(), err, tt.wantErr)
+			}
+			if len(game.Guesses) != 1 || game.GuessesRemaining() != 6 {
+				t.Errorf("game has %d guesses with %d left, want 1 with 6", len(game.Guesses), game.GuessesRemaining())
+			}
+			for i, board := range game.Boards {
+				if len(board.Guesses) != 1 {
+					t.Errorf("board %d has %d guesses, want 1", i, len(board.Guesses))
+				}
+			}
+		})
+	}
+}
+
+func TestEngine_NewRandomMultiGame(t *testing.T) {
+	e := mustNewEngine(t, []string{"apple", "grape", "lemon", "mango"}, []string{"apple", "grape", "mango"})
+
+	game, err := e.NewRandomMultiGame(3, 0)
+	if err != nil {
+		t.Fatalf("NewRandomMultiGame() error = %v", err)
+	}
+	var solutions []string
+	for _, board := range game.Boards {
+		if board.Engine() != e {
+			t.Error("board is not played against the engine")
+		}
+		solutions = append(solutions, board.Solution.String())
+	}
+	slices.Sort(solutions)
+	if !slices.Equal(solutions, []string{"apple", "grape", "mango"}) {
+		t.Errorf("solutions = %v, want each allowed solution once", solutions)
+	}
+
+	if _, err := e.NewRandomMultiGame(4, 0); !errors.Is(err, ErrInvalidBoards) {
+		t.Errorf("NewRandomMultiGame() with more boards than solutions error = %v, want ErrInvalidBoards", err)
+	}
+}
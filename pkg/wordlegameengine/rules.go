@@ -0,0 +1,111 @@
+package wordlegameengine
+
+import (
+	"errors"
+	"fmt"
+)
+
+// NoGuessLimit is a Rules.MaxGuesses allowing any number of guesses
+const NoGuessLimit = -1
+
+var ErrInvalidRules = errors.New("invalid rules")
+
+// ErrGuessNotSolution rejects a guess that is an allowed guess, but not an allowed solution, in a
+// game whose guesses must come from the solutions. It wraps ErrNotInWordlist
+var ErrGuessNotSolution = fmt.Errorf("%w: guesses must be allowed solutions", ErrNotInWordlist)
+
+// GuessList selects the wordlist that guesses must come from
+type GuessList int8
+
+const (
+	GuessFromAllowedGuesses GuessList = iota // Any of the engine's allowed guesses
+	GuessFromSolutions                       // Only words that could be the solution
+)
+
+func (l GuessList) String() string {
+	switch l {
+	case GuessFromSolutions:
+		return "solutions"
+	default:
+		return "guesses"
+	}
+}
+
+// ParseGuessList parses the name of a GuessList, as given by its String method
+func ParseGuessList(s string) (GuessList, error) {
+	switch s {
+	case "guesses":
+		return GuessFromAllowedGuesses, nil
+	case "solutions":
+		return GuessFromSolutions, nil
+	default:
+		return 0, fmt.Errorf("guess list %q: %w", s, ErrInvalidRules)
+	}
+}
+
+// Rules configures how a game is played. The zero value is the standard Wordle rules
+type Rules struct {
+	MaxGuesses   int       // 0 means the standard MaxGuesses, and NoGuessLimit means no limit
+	HardMode     bool      // Every guess must use the hints revealed so far; see CheckHardMode
+	GuessList    GuessList // The wordlist guesses must come from
+	AllowRepeats bool      // Whether a word may be guessed more than once
+}
+
+// DefaultRules returns the standard Wordle rules
+func DefaultRules() Rules {
+	return Rules{MaxGuesses: MaxGuesses}
+}
+
+// Validate checks that the rules can be played
+func (r Rules) Validate() error {
+	if r.MaxGuesses < NoGuessLimit {
+		return fmt.Errorf("%d guesses: %w", r.MaxGuesses, ErrInvalidMaxGuesses)
+	}
+	if r.GuessList != GuessFromAllowedGuesses && r.GuessList != GuessFromSolutions {
+		return fmt.Errorf("guess list %d: %w", r.GuessList, ErrInvalidRules)
+	}
+	return nil
+}
+
+// guessLimit returns the maximum number of guesses, or 0 for no limit
+func (r Rules) guessLimit() int {
+	switch r.MaxGuesses {
+	case 0:
+		return MaxGuesses
+	case NoGuessLimit:
+		return 0
+	default:
+		return r.MaxGuesses
+	}
+}
+
+// checkRules checks that guess may be played next under the game's rules: the game is not over,
+// the guess is an allowed guess of the engine and on the rules' guess list, it is not a repeat
+// unless allowed, and in hard mode it uses the hints revealed so far
+func (g *Game) checkRules(guess Word) error {
+	if end := g.finalTurn(); end != -1 {
+		if g.Feedbacks[end].AllGreen() {
+			return ErrGameOver
+		}
+		return fmt.Errorf("all %d guesses used: %w", g.Rules.guessLimit(), ErrGameOver)
+	}
+	if err := g.Engine().ValidateWord(guess); err != nil {
+		return err
+	}
+	if g.Rules.GuessList == GuessFromSolutions {
+		if _, ok := g.Engine().solutionIndex(guess); !ok {
+			return &ValidationError{Field: FieldGuess, Value: guess.String(), Position: -1, Err: ErrGuessNotSolution}
+		}
+	}
+	if !g.Rules.AllowRepeats {
+		for _, previous := range g.Guesses {
+			if previous == guess {
+				return &ValidationError{Field: FieldGuess, Value: guess.String(), Position: -1, Err: ErrRepeatedGuess}
+			}
+		}
+	}
+	if g.Rules.HardMode {
+		return CheckHardMode(guess, g.Guesses, g.Feedbacks)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package wordlegameengine
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRules_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   Rules
+		wantErr error
+	}{
+		{"zero value", Rules{}, nil},
+		{"default", DefaultRules(), nil},
+		{"no limit", Rules{MaxGuesses: NoGuessLimit}, nil},
+		{"solutions only", Rules{MaxGuesses: 3, GuessList: GuessFromSolutions}, nil},
+		{"negative limit", Rules{MaxGuesses: -2}, ErrInvalidMaxGuesses},
+		{"unknown guess list", Rules{GuessList: 7}, ErrInvalidRules},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.rules.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+			if _, err := NewGameWithRules(mustNewSolution("crane"), tt.rules); !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewGameWithRules() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseGuessList(t *testing.T) {
+	for _, want := range []GuessList{GuessFromAllowedGuesses, GuessFromSolutions} {
+		got, err := ParseGuessList(want.String())
+		if err != nil || got != want {
+			t.Errorf("ParseGuessList(%q) = %v, %v, want %v", want.String(), got, err, want)
+		}
+	}
+	if _, err := ParseGuessList("answers"); !errors.Is(err, ErrInvalidRules) {
+		t.Errorf("ParseGuessList(\"answers\") error = %v, want ErrInvalidRules", err)
+	}
+}
+
+func TestGame_Rules_MaxGuesses(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxGuesses int
+		plays      int
+		wantStatus GameStatus
+	}{
+		{"standard", 0, MaxGuesses, StatusLost},
+		{"short game", 2, 2, StatusLost},
+		{"long game", 8, 6, StatusOngoing},
+		{"no limit", NoGuessLimit, 10, StatusOngoing},
+	}
+	guesses := mustNewWords("moist", "pudgy", "slate", "audio", "brick", "fjord", "lymph", "vexed", "whelk", "zonal")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game, err := NewGameWithRules(mustNewSolution("crane"), Rules{MaxGuesses: tt.maxGuesses})
+			if err != nil {
+				t.Fatalf("NewGameWithRules() error = %v", err)
+			}
+			for _, guess := range guesses[:tt.plays] {
+				if err := game.PlayGuess(guess); err != nil {
+					t.Fatalf("PlayGuess(%q) error = %v", guess.String(), err)
+				}
+			}
+			if got := game.Status(); got != tt.wantStatus {
+				t.Errorf("Status() = %v, want %v", got, tt.wantStatus)
+			}
+			err = game.PlayGuess(mustNewWord("crane"))
+			if tt.wantStatus == StatusLost && !errors.Is(err, ErrGameOver) {
+				t.Errorf("PlayGuess() after the last guess error = %v, want ErrGameOver", err)
+			}
+			if tt.wantStatus == StatusOngoing && (err != nil || !game.Won()) {
+				t.Errorf("PlayGuess() error = %v, won = %v, want a win", err, game.Won())
+			}
+		})
+	}
+}
+
+func TestGame_Rules_GameOver(t *testing.T) {
+	game := NewGame(mustNewSolution("crane"))
+	if err := game.PlayGuess(mustNewWord("crane")); err != nil {
+		t.Fatalf("PlayGuess() error = %v", err)
+	}
+	if err := game.PlayGuess(mustNewWord("slate")); !errors.Is(err, ErrGameOver) {
+		t.Errorf("PlayGuess() after winning error = %v, want ErrGameOver", err)
+	}
+	if err := game.ReplayTurn(mustNewWord("slate"), Feedback{Grey, Grey, Green, Grey, Green}); !errors.Is(err, ErrGameOver) {
+		t.Errorf("ReplayTurn() after winning error = %v, want ErrGameOver", err)
+	}
+	if len(game.Guesses) != 1 {
+		t.Errorf("game has %d guesses, want 1", len(game.Guesses))
+	}
+}
+
+func TestGame_Rules_GuessList(t *testing.T) {
+	e := mustNewEngine(t, []string{"crane", "slate", "xylyl"}, []string{"crane", "slate"})
+	game, err := e.NewGameWithRules(mustNewSolution("crane"), Rules{GuessList: GuessFromSolutions})
+	if err != nil {
+		t.Fatalf("NewGameWithRules() error = %v", err)
+	}
+
+	err = game.PlayGuess(mustNewWord("xylyl"))
+	if !errors.Is(err, ErrGuessNotSolution) || !errors.Is(err, ErrNotInWordlist) {
+		t.Errorf("PlayGuess() of a guess that is not a solution error = %v, want ErrGuessNotSolution", err)
+	}
+	if err := game.ReplayTurn(mustNewWord("xylyl"), allGrey); !errors.Is(err, ErrGuessNotSolution) {
+		t.Errorf("ReplayTurn() of a guess that is not a solution error = %v, want ErrGuessNotSolution", err)
+	}
+	if err := game.PlayGuess(mustNewWord("slate")); err != nil {
+		t.Errorf("PlayGuess() of a solution error = %v", err)
+	}
+}
+
+func TestGame_Rules_AllowRepeats(t *testing.T) {
+	for _, allow := range []bool{false, true} {
+		game, err := NewGameWithRules(mustNewSolution("crane"), Rules{AllowRepeats: allow})
+		if err != nil {
+			t.Fatalf("NewGameWithRules() error = %v", err)
+		}
+		if err := game.PlayGuess(mustNewWord("slate")); err != nil {
+			t.Fatalf("PlayGuess() error = %v", err)
+		}
+		err = game.PlayGuess(mustNewWord("slate"))
+		if allow && err != nil {
+			t.Errorf("repeated PlayGuess() with repeats allowed error = %v", err)
+		}
+		if !allow && !errors.Is(err, ErrRepeatedGuess) {
+			t.Errorf("repeated PlayGuess() error = %v, want ErrRepeatedGuess", err)
+		}
+	}
+}
+
+func TestGame_Rules_HardMode(t *testing.T) {
+	game, err := NewGameWithRules(mustNewSolution("crane"), Rules{HardMode: true})
+	if err != nil {
+		t.Fatalf("NewGameWithRules() error = %v", err)
+	}
+	if err := game.PlayGuess(mustNewWord("slate")); err != nil {
+		t.Fatalf("PlayGuess() error = %v", err)
+	}
+	if err := game.PlayGuess(mustNewWord("moist")); !errors.Is(err, ErrHardModeViolation) {
+		t.Errorf("PlayGuess() ignoring a hint error = %v, want ErrHardModeViolation", err)
+	}
+	if err := game.PlayGuess(mustNewWord("brace")); err != nil {
+		t.Errorf("PlayGuess() using the hints error = %v", err)
+	}
+}
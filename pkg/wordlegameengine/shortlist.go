@@ -0,0 +1,289 @@
+package wordlegameengine
+
+import (
+	"container/list"
+	"iter"
+	"math/bits"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Shortlist is a set of candidate solutions, stored as a bitset over indexes into an engine's
+// allowed solutions (one bit per word, ~289 bytes for the standard list). Shortlists are immutable:
+// operations return a new Shortlist, so they can be shared between games and the cache without
+// copying. Iteration is in wordlist (alphabetical) order. The zero value is the empty shortlist
+type Shortlist struct {
+	bits      []uint64
+	solutions []Word // The wordlist the bits index into
+}
+
+// FullShortlist returns a shortlist holding every word in AllowedSolutions
+func FullShortlist() Shortlist {
+	return Default().FullShortlist()
+}
+
+// NewShortlist returns a shortlist holding the given words. Words not in AllowedSolutions are left
+// out, as they can never be the solution
+func NewShortlist(words []Word) Shortlist {
+	return Default().NewShortlist(words)
+}
+
+// FullShortlist returns a shortlist holding every allowed solution
+func (e *Engine) FullShortlist() Shortlist {
+	n := len(e.solutions)
+	s := e.emptyShortlist()
+	for i := range s.bits {
+		s.bits[i] = ^uint64(0)
+	}
+	if n%64 != 0 {
+		s.bits[len(s.bits)-1] = (uint64(1) << (n % 64)) - 1
+	}
+	return s
+}
+
+// NewShortlist returns a shortlist holding the given words. Words that are not allowed solutions
+// are left out, as they can never be the solution
+func (e *Engine) NewShortlist(words []Word) Shortlist {
+	s := e.emptyShortlist()
+	for _, w := range words {
+		if idx, ok := e.solutionIndex(w); ok {
+			s.bits[idx/64] |= 1 << (idx % 64)
+		}
+	}
+	return s
+}
+
+// emptyShortlist returns a shortlist with room for every allowed solution, and none set
+func (e *Engine) emptyShortlist() Shortlist {
+	return Shortlist{bits: make([]uint64, (len(e.solutions)+63)/64), solutions: e.solutions}
+}
+
+// Count returns the number of words on the shortlist
+func (s Shortlist) Count() int {
+	count := 0
+	for _, b := range s.bits {
+		count += bits.OnesCount64(b)
+	}
+	return count
+}
+
+// Contains reports whether w is on the shortlist
+func (s Shortlist) Contains(w Word) bool {
+	idx, ok := slices.BinarySearchFunc(s.solutions, w, Word.Compare)
+	return ok && s.has(idx)
+}
+
+func (s Shortlist) has(idx int) bool {
+	return idx/64 < len(s.bits) && s.bits[idx/64]&(1<<(idx%64)) != 0
+}
+
+// Intersect returns the words on both s and other
+func (s Shortlist) Intersect(other Shortlist) Shortlist {
+	result := Shortlist{bits: make([]uint64, min(len(s.bits), len(other.bits))), solutions: s.solutions}
+	if result.solutions == nil {
+		result.solutions = other.solutions
+	}
+	for i := range result.bits {
+		result.bits[i] = s.bits[i] & other.bits[i]
+	}
+	return result
+}
+
+// Indexes iterates over the AllowedSolutions indexes of the words on the shortlist, in order
+func (s Shortlist) Indexes() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for i, b := range s.bits {
+			for b != 0 {
+				idx := i*64 + bits.TrailingZeros64(b)
+				if !yield(idx) {
+					return
+				}
+				b &= b - 1
+			}
+		}
+	}
+}
+
+// All iterates over the words on the shortlist, in wordlist order
+func (s Shortlist) All() iter.Seq[Word] {
+	return func(yield func(Word) bool) {
+		for idx := range s.Indexes() {
+			if !yield(s.solutions[idx]) {
+				return
+			}
+		}
+	}
+}
+
+// Words returns the words on the shortlist, in wordlist order
+func (s Shortlist) Words() []Word {
+	words := make([]Word, 0, s.Count())
+	for w := range s.All() {
+		words = append(words, w)
+	}
+	return words
+}
+
+// solutionIndex returns the position of w in the engine's allowed solutions, which are sorted
+func (e *Engine) solutionIndex(w Word) (int, bool) {
+	return slices.BinarySearchFunc(e.solutions, w, Word.Compare)
+}
+
+// maxMemoisedGuesses bounds the feedback masks kept in memory per engine. Each guess holds one
+// mask per feedback that occurs, typically ~40KB in total
+const maxMemoisedGuesses = 2048
+
+// maskMemo memoises, per guess, the shortlist of solutions giving each feedback code, so that
+// filtering by a turn is a single Intersect. Past maxGuesses, guesses are evicted by CLOCK as in
+// ShortlistCache
+type maskMemo struct {
+	sync.RWMutex
+	byGuess    map[Word]*list.Element // Holding a *memoisedMasks
+	clock      *list.List             // Newest first; eviction starts from the back
+	maxGuesses int
+}
+
+// memoisedMasks is a guess's masks and its place in the memo's eviction order. Its reference bit
+// is set whenever the masks are used, which needs only the read lock
+type memoisedMasks struct {
+	guess      Word
+	masks      feedbackMasks
+	referenced atomic.Bool
+}
+
+func newMaskMemo(maxGuesses int) *maskMemo {
+	return &maskMemo{
+		byGuess:    make(map[Word]*list.Element),
+		clock:      list.New(),
+		maxGuesses: maxGuesses,
+	}
+}
+
+// get returns the memoised masks of guess, marking them as used
+func (m *maskMemo) get(guess Word) (feedbackMasks, bool) {
+	m.RLock()
+	defer m.RUnlock()
+
+	elem, ok := m.byGuess[guess]
+	if !ok {
+		return feedbackMasks{}, false
+	}
+	memo := elem.Value.(*memoisedMasks)
+	memo.referenced.Store(true)
+	return memo.masks, true
+}
+
+// put memoises the masks of guess, evicting another guess if the memo is full. Masks another caller
+// memoised first are kept
+func (m *maskMemo) put(guess Word, masks feedbackMasks) {
+	m.Lock()
+	defer m.Unlock()
+
+	if _, ok := m.byGuess[guess]; ok {
+		return
+	}
+	if len(m.byGuess) >= m.maxGuesses {
+		m.evictOldest()
+	}
+	m.byGuess[guess] = m.clock.PushFront(&memoisedMasks{guess: guess, masks: masks})
+}
+
+// evictOldest removes the oldest guess not used since eviction last passed over it, giving used
+// guesses a second chance. The caller holds the write lock
+func (m *maskMemo) evictOldest() {
+	oldest := m.clock.Back()
+	for memo := oldest.Value.(*memoisedMasks); memo.referenced.Load(); memo = oldest.Value.(*memoisedMasks) {
+		memo.referenced.Store(false)
+		m.clock.MoveToFront(oldest)
+		oldest = m.clock.Back()
+	}
+	m.clock.Remove(oldest)
+	delete(m.byGuess, oldest.Value.(*memoisedMasks).guess)
+}
+
+// feedbackMasks holds, for one guess, the shortlist of solutions giving each feedback that occurs.
+// Only those feedbacks are stored, as most of the NumFeedbacks codes of long words never occur
+type feedbackMasks struct {
+	codes []uint16 // Sorted
+	masks []Shortlist
+}
+
+// get returns the mask of the feedback code, or the zero Shortlist if no solution gives it
+func (m feedbackMasks) get(code uint16) Shortlist {
+	if i, ok := slices.BinarySearch(m.codes, code); ok {
+		return m.masks[i]
+	}
+	return Shortlist{}
+}
+
+// feedbackMask returns the shortlist of every allowed solution that gives feedback for guess
+func (e *Engine) feedbackMask(guess Word, feedback Feedback) Shortlist {
+	if guess.Len() != e.wordLength || feedback.Len() != e.wordLength {
+		return e.emptyShortlist()
+	}
+	code := feedback.Encode()
+
+	masks, ok := e.masks.get(guess)
+	if !ok {
+		masks = e.buildFeedbackMasks(guess)
+		e.masks.put(guess, masks)
+	}
+	return masks.get(code)
+}
+
+// buildFeedbackMasks partitions the allowed solutions by the feedback each gives for guess
+func (e *Engine) buildFeedbackMasks(guess Word) feedbackMasks {
+	codes := e.feedbackCodes(guess)
+	n := (len(codes) + 63) / 64
+
+	// Number the feedbacks that occur in code order, so the masks come out sorted
+	position := make([]int32, e.numFeedbacks)
+	for _, code := range codes {
+		position[code] = 1
+	}
+	var m feedbackMasks
+	for code, occurs := range position {
+		if occurs != 0 {
+			position[code] = int32(len(m.codes))
+			m.codes = append(m.codes, uint16(code))
+			m.masks = append(m.masks, Shortlist{bits: make([]uint64, n), solutions: e.solutions})
+		}
+	}
+
+	for idx, code := range codes {
+		m.masks[position[code]].bits[idx/64] |= 1 << (idx % 64)
+	}
+	return m
+}
+
+// feedbackCodes returns the encoded feedback for guess against each allowed solution, using the
+// engine's feedback table where it covers the words
+func (e *Engine) feedbackCodes(guess Word) []uint16 {
+	codes := make([]uint16, len(e.solutions))
+
+	table := e.table
+	var row FeedbackRow
+	if table != nil {
+		if guessIdx, ok := table.GuessIndex(guess); ok {
+			row = table.Row(guessIdx)
+		}
+	}
+
+	for idx, word := range e.solutions {
+		if row.Len() > 0 {
+			if solutionIdx, ok := table.SolutionIndex(word); ok {
+				codes[idx] = row.At(solutionIdx)
+				continue
+			}
+		}
+		solution := Solution(word)
+		codes[idx] = solution.CheckGuess(guess).Encode()
+	}
+	return codes
+}
+
+// sameWordlist reports whether a and b are the same slice, not just equal contents
+func sameWordlist(a, b []Word) bool {
+	return len(a) == len(b) && (len(a) == 0 || &a[0] == &b[0])
+}
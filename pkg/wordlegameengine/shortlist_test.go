@@ -0,0 +1,187 @@
+package wordlegameengine
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFullShortlist(t *testing.T) {
+	full := FullShortlist()
+
+	if full.Count() != len(AllowedSolutions) {
+		t.Errorf("Count() = %d, want %d", full.Count(), len(AllowedSolutions))
+	}
+	if !slices.Equal(full.Words(), AllowedSolutions) {
+		t.Error("Words() differs from AllowedSolutions")
+	}
+}
+
+func TestNewShortlist(t *testing.T) {
+	tests := []struct {
+		name  string
+		words []string
+		want  []string
+	}{
+		{"empty", nil, nil},
+		{"sorted into wordlist order", []string{"stare", "apple", "crane"}, []string{"apple", "crane", "stare"}},
+		{"duplicates", []string{"crane", "crane"}, []string{"crane"}},
+		{"guess that cannot be the solution", []string{"aahed", "crane"}, []string{"crane"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			words := make([]Word, len(tt.words))
+			for i, w := range tt.words {
+				words[i] = mustNewWord(w)
+			}
+			want := make([]Word, len(tt.want))
+			for i, w := range tt.want {
+				want[i] = mustNewWord(w)
+			}
+
+			s := NewShortlist(words)
+			if got := s.Words(); !slices.Equal(got, want) {
+				t.Errorf("Words() = %v, want %v", got, want)
+			}
+			if s.Count() != len(want) {
+				t.Errorf("Count() = %d, want %d", s.Count(), len(want))
+			}
+		})
+	}
+}
+
+func TestShortlist_ZeroValue(t *testing.T) {
+	var s Shortlist
+
+	if s.Count() != 0 {
+		t.Errorf("Count() = %d, want 0", s.Count())
+	}
+	if s.Contains(mustNewWord("crane")) {
+		t.Error("Contains(\"crane\") = true for the empty shortlist")
+	}
+	if got := FullShortlist().Intersect(s).Count(); got != 0 {
+		t.Errorf("Intersect() with the empty shortlist has %d words, want 0", got)
+	}
+}
+
+func TestShortlist_Intersect(t *testing.T) {
+	a := NewShortlist([]Word{mustNewWord("apple"), mustNewWord("crane"), mustNewWord("stare")})
+	b := NewShortlist([]Word{mustNewWord("crane"), mustNewWord("stare"), mustNewWord("slate")})
+
+	got := a.Intersect(b)
+	want := []Word{mustNewWord("crane"), mustNewWord("stare")}
+	if !slices.Equal(got.Words(), want) {
+		t.Errorf("Intersect() = %v, want %v", got.Words(), want)
+	}
+	if a.Count() != 3 || b.Count() != 3 {
+		t.Error("Intersect() modified its operands")
+	}
+}
+
+func TestShortlist_Contains(t *testing.T) {
+	s := NewShortlist([]Word{mustNewWord("crane")})
+
+	if !s.Contains(mustNewWord("crane")) {
+		t.Error("Contains(\"crane\") = false, want true")
+	}
+	if s.Contains(mustNewWord("slate")) {
+		t.Error("Contains(\"slate\") = true, want false")
+	}
+	if s.Contains(mustNewWord("aahed")) {
+		t.Error("Contains(\"aahed\") = true for a word outside AllowedSolutions")
+	}
+}
+
+func TestShortlist_Iteration(t *testing.T) {
+	s := NewShortlist([]Word{mustNewWord("stare"), mustNewWord("apple"), mustNewWord("crane")})
+
+	var idxs []int
+	for idx := range s.Indexes() {
+		idxs = append(idxs, idx)
+	}
+	for i, idx := range idxs {
+		if AllowedSolutions[idx] != s.Words()[i] {
+			t.Errorf("Indexes()[%d] = %d (%q), want the index of %q", i, idx, AllowedSolutions[idx].String(), s.Words()[i].String())
+		}
+	}
+
+	// Stopping early
+	for w := range s.All() {
+		if w != mustNewWord("apple") {
+			t.Errorf("first word = %q, want apple", w.String())
+		}
+		break
+	}
+}
+
+func TestFeedbackMask(t *testing.T) {
+	oldTable := PrecomputedFeedback
+	defer func() { PrecomputedFeedback = oldTable }()
+
+	guess := mustNewWord("slate")
+	feedback := Feedback{Grey, Grey, Green, Grey, Green}
+
+	var want []Word
+	for _, word := range AllowedSolutions {
+		solution := Solution(word)
+		if solution.CheckGuess(guess) == feedback {
+			want = append(want, word)
+		}
+	}
+
+	PrecomputedFeedback = nil
+	if got := Default().feedbackMask(guess, feedback).Words(); !slices.Equal(got, want) {
+		t.Errorf("feedbackMask() = %v, want %v", got, want)
+	}
+
+	// Memoised masks are reused
+	if got := Default().feedbackMask(guess, feedback).Words(); !slices.Equal(got, want) {
+		t.Errorf("feedbackMask() second call = %v, want %v", got, want)
+	}
+
+	// Feedback no solution gives
+	if got := Default().feedbackMask(guess, Feedback{Green, Green, Green, Green, Yellow}).Count(); got != 0 {
+		t.Errorf("feedbackMask() for impossible feedback has %d words, want 0", got)
+	}
+
+	// A table built against other wordlists is used only where it covers the words
+	PrecomputedFeedback = NewFeedbackTable([]Word{guess}, AllowedSolutions[:10])
+	masks := Default().buildFeedbackMasks(guess)
+	if got := masks.get(feedback.Encode()).Words(); !slices.Equal(got, want) {
+		t.Errorf("buildFeedbackMasks() with partial table = %v, want %v", got, want)
+	}
+
+	// Only the feedbacks that occur are stored, in code order
+	occurring := make(map[uint16]bool)
+	for _, word := range AllowedSolutions {
+		solution := Solution(word)
+		occurring[solution.CheckGuess(guess).Encode()] = true
+	}
+	if len(masks.codes) != len(occurring) || !slices.IsSorted(masks.codes) {
+		t.Errorf("buildFeedbackMasks() stored %d codes (sorted %v), want the %d that occur",
+			len(masks.codes), slices.IsSorted(masks.codes), len(occurring))
+	}
+}
+
+func TestMaskMemo_Eviction(t *testing.T) {
+	memo := newMaskMemo(2)
+	words := mustNewWords("crane", "slate", "moist")
+	for _, word := range words[:2] {
+		memo.put(word, feedbackMasks{codes: []uint16{0}})
+	}
+
+	// The used guess gets a second chance, so the unused one is evicted
+	if _, ok := memo.get(words[0]); !ok {
+		t.Fatalf("get(%q) missed", words[0].String())
+	}
+	memo.put(words[2], feedbackMasks{})
+
+	for i, want := range []bool{true, false, true} {
+		if _, ok := memo.get(words[i]); ok != want {
+			t.Errorf("get(%q) found = %v, want %v", words[i].String(), ok, want)
+		}
+	}
+	if len(memo.byGuess) != 2 || memo.clock.Len() != 2 {
+		t.Errorf("memo holds %d guesses and %d clock entries, want 2", len(memo.byGuess), memo.clock.Len())
+	}
+}
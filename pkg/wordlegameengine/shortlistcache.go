@@ -1,24 +1,63 @@
 package wordlegameengine
 
 import (
+	"container/list"
+	"errors"
 	"fmt"
+	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/google/btree"
 )
 
-// CacheKey is a string in format "guess|feedback" (e.g., "raise|-G---")
+// CacheKey is a string in format "guess|feedback" (e.g., "raise|-G---") for a single turn, or
+// several such turns joined by ";" for a turn history (e.g., "raise|-G---;clout|--Y--")
 type CacheKey string
 
+const turnSeparator = ";"
+
 // MakeCacheKey creates a cache key from a guess and feedback
 func MakeCacheKey(guess Word, feedback Feedback) CacheKey {
 	return CacheKey(fmt.Sprintf("%s|%s", guess.String(), feedback.String()))
 }
 
+// MakeTurnsKey creates a cache key from a turn history. A single turn gives the same key as
+// MakeCacheKey, and no turns give the empty key
+func MakeTurnsKey(guesses []Word, feedbacks []Feedback) CacheKey {
+	keys := prefixKeys(guesses, feedbacks)
+	if len(keys) == 0 {
+		return ""
+	}
+	return keys[len(keys)-1]
+}
+
+// prefixKeys returns the key of every prefix of a turn history, shortest first
+func prefixKeys(guesses []Word, feedbacks []Feedback) []CacheKey {
+	keys := make([]CacheKey, len(guesses))
+	var b strings.Builder
+	for i := range guesses {
+		if i > 0 {
+			b.WriteString(turnSeparator)
+		}
+		b.WriteString(string(MakeCacheKey(guesses[i], feedbacks[i])))
+		keys[i] = CacheKey(b.String())
+	}
+	return keys
+}
+
 // CacheEntry implements btree.Item interface
 type CacheEntry struct {
 	Key       CacheKey
-	Shortlist []Word // Copy of the shortlist
+	Shortlist Shortlist
+	clockElem *list.Element // Position in the cache's eviction order, holding a *clockRef
+}
+
+// clockRef is an entry's place in the eviction order. Its reference bit is set whenever the entry
+// is used, which needs only the read lock, and cleared when eviction passes over it
+type clockRef struct {
+	key        CacheKey
+	referenced atomic.Bool
 }
 
 // Less implements btree.Item for ordering
@@ -30,64 +69,233 @@ func (e CacheEntry) Less(than btree.Item) bool {
 	return e.Key < other.Key
 }
 
-// ShortlistCache is a thread-safe B-tree cache
+// cacheEntryOverhead estimates the memory used by an entry beyond its key and shortlist bits: the
+// entry and its slice headers in the B-tree, and its clock list element
+const cacheEntryOverhead = 160
+
+// size estimates the memory used by the entry, in bytes
+func (e CacheEntry) size() int64 {
+	return int64(len(e.Key) + 8*len(e.Shortlist.bits) + cacheEntryOverhead)
+}
+
+// CacheStats is a snapshot of a cache's counters
+type CacheStats struct {
+	Hits         uint64
+	Misses       uint64
+	Deduplicated uint64 // GetOrCompute calls that waited for a computation already in flight
+	Evictions    uint64
+	Entries      int
+	Bytes        int64 // Estimated memory in use
+	MaxBytes     int64 // Budget; 0 means unbounded
+}
+
+// cacheCall is a GetOrCompute computation in flight, which callers for the same key wait on
+type cacheCall struct {
+	done      chan struct{}
+	shortlist Shortlist
+	err       error
+}
+
+var errComputeAborted = errors.New("shortlist computation did not complete")
+
+// ShortlistCache is a thread-safe B-tree cache. When the estimated size of its entries exceeds
+// the byte budget, entries are evicted by the CLOCK algorithm, an approximation of least recently
+// used: entries not used since eviction last passed over them go first. Lookups only set a
+// reference bit, so they run concurrently under the read lock
 type ShortlistCache struct {
-	tree   *btree.BTree
-	mutex  sync.RWMutex
-	degree int
+	tree     *btree.BTree
+	clock    *list.List // Entries' *clockRef, newest first; eviction starts from the back
+	mutex    sync.RWMutex
+	degree   int
+	maxBytes int64
+	stats    CacheStats // Hits and Misses are kept in hits and misses
+	hits     atomic.Uint64
+	misses   atomic.Uint64
+	inflight map[CacheKey]*cacheCall
 }
 
 const BTreeDegree = 32
 
-// NewShortlistCache creates a new cache with BTreeDegree = 32
+// DefaultCacheBytes is the default cache budget, roughly 10GB
+const DefaultCacheBytes = 10 << 30
+
+// NewShortlistCache creates a new cache with BTreeDegree = 32 and the default budget
 func NewShortlistCache() *ShortlistCache {
+	return NewShortlistCacheWithBudget(DefaultCacheBytes)
+}
+
+// NewShortlistCacheWithBudget creates a new cache holding at most maxBytes of entries, by
+// estimate. A budget of 0 or less means the cache is unbounded
+func NewShortlistCacheWithBudget(maxBytes int64) *ShortlistCache {
 	return &ShortlistCache{
-		tree:   btree.New(BTreeDegree),
-		degree: BTreeDegree,
+		tree:     btree.New(BTreeDegree),
+		clock:    list.New(),
+		degree:   BTreeDegree,
+		maxBytes: max(maxBytes, 0),
+		inflight: make(map[CacheKey]*cacheCall),
 	}
 }
 
-// Get retrieves a shortlist from cache (thread-safe)
-func (c *ShortlistCache) Get(key CacheKey) ([]Word, bool) {
+// Get retrieves a shortlist from cache (thread-safe). Shortlists are immutable, so the cached
+// value is returned without copying
+func (c *ShortlistCache) Get(key CacheKey) (Shortlist, bool) {
 	c.mutex.RLock()
 	defer c.mutex.RUnlock()
 
+	entry, ok := c.lookup(key)
+	if !ok {
+		c.misses.Add(1)
+		return Shortlist{}, false
+	}
+	c.hits.Add(1)
+	return entry.Shortlist, true
+}
+
+// GetOrCompute retrieves a shortlist from cache, or computes and stores it on a miss (thread-safe).
+// Concurrent calls for the same key share one computation: the first runs compute, and the rest
+// wait for its result. Errors are returned to every waiting caller, and not cached
+func (c *ShortlistCache) GetOrCompute(key CacheKey, compute func() (Shortlist, error)) (Shortlist, error) {
+	c.mutex.RLock()
+	entry, ok := c.lookup(key)
+	c.mutex.RUnlock()
+	if ok {
+		c.hits.Add(1)
+		return entry.Shortlist, nil
+	}
+
+	// Check again under the write lock, as the entry may have been stored in between
+	c.mutex.Lock()
+	if entry, ok := c.lookup(key); ok {
+		c.hits.Add(1)
+		c.mutex.Unlock()
+		return entry.Shortlist, nil
+	}
+	if call, ok := c.inflight[key]; ok {
+		c.stats.Deduplicated++
+		c.mutex.Unlock()
+		<-call.done
+		return call.shortlist, call.err
+	}
+	call := &cacheCall{done: make(chan struct{}), err: errComputeAborted}
+	c.inflight[key] = call
+	c.misses.Add(1)
+	c.mutex.Unlock()
+
+	// Release the waiters even if compute panics
+	defer func() {
+		c.mutex.Lock()
+		delete(c.inflight, key)
+		c.mutex.Unlock()
+		close(call.done)
+	}()
+
+	call.shortlist, call.err = compute()
+	if call.err == nil {
+		c.Put(key, call.shortlist)
+	}
+	return call.shortlist, call.err
+}
+
+// LongestPrefix finds the longest prefix of a turn history with a cached shortlist (thread-safe).
+// It returns the number of turns the prefix covers and its shortlist, or 0 if no prefix is cached.
+// Finding a prefix counts as a hit; finding none is not counted as a miss, as the turns are
+// expected to be looked up again with GetOrCompute
+func (c *ShortlistCache) LongestPrefix(guesses []Word, feedbacks []Feedback) (int, Shortlist) {
+	keys := prefixKeys(guesses, feedbacks)
+
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	for n := len(keys); n > 0; n-- {
+		if entry, ok := c.lookup(keys[n-1]); ok {
+			c.hits.Add(1)
+			return n, entry.Shortlist
+		}
+	}
+	return 0, Shortlist{}
+}
+
+// lookup finds an entry and sets its reference bit. The caller must hold the read or write lock
+func (c *ShortlistCache) lookup(key CacheKey) (CacheEntry, bool) {
 	item := c.tree.Get(CacheEntry{Key: key})
 	if item == nil {
-		return nil, false
+		return CacheEntry{}, false
 	}
-
 	entry, ok := item.(CacheEntry)
 	if !ok {
-		return nil, false
+		return CacheEntry{}, false
 	}
-
-	// Return a copy to prevent external modification
-	shortlistCopy := make([]Word, len(entry.Shortlist))
-	copy(shortlistCopy, entry.Shortlist)
-	return shortlistCopy, true
+	entry.clockElem.Value.(*clockRef).referenced.Store(true)
+	return entry, true
 }
 
-// Put stores a shortlist in cache (thread-safe, makes copy of shortlist)
-func (c *ShortlistCache) Put(key CacheKey, shortlist []Word) {
+// Put stores a shortlist in cache (thread-safe), evicting entries to stay within the budget. A shortlist too large for the budget on its own is not stored
+func (c *ShortlistCache) Put(key CacheKey, shortlist Shortlist) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	// Make a copy of the shortlist to store
-	shortlistCopy := make([]Word, len(shortlist))
-	copy(shortlistCopy, shortlist)
-
 	entry := CacheEntry{
 		Key:       key,
-		Shortlist: shortlistCopy,
+		Shortlist: shortlist,
+	}
+	if c.maxBytes > 0 && entry.size() > c.maxBytes {
+		return
+	}
+
+	if old, ok := c.lookup(key); ok {
+		entry.clockElem = old.clockElem
+		c.stats.Bytes -= old.size()
+	} else {
+		entry.clockElem = c.clock.PushFront(&clockRef{key: key})
 	}
 	c.tree.ReplaceOrInsert(entry)
+	c.stats.Bytes += entry.size()
+
+	for c.maxBytes > 0 && c.stats.Bytes > c.maxBytes {
+		c.evictOldest()
+	}
+}
+
+// evictOldest removes the oldest entry not used since eviction last passed over it. Used entries
+// on the way have their reference bit cleared and are moved to the front, as a second chance. The
+// caller must hold the write lock
+func (c *ShortlistCache) evictOldest() {
+	oldest := c.clock.Back()
+	for ref := oldest.Value.(*clockRef); ref.referenced.Load(); ref = oldest.Value.(*clockRef) {
+		ref.referenced.Store(false)
+		c.clock.MoveToFront(oldest)
+		oldest = c.clock.Back()
+	}
+	c.clock.Remove(oldest)
+	if item := c.tree.Delete(CacheEntry{Key: oldest.Value.(*clockRef).key}); item != nil {
+		c.stats.Bytes -= item.(CacheEntry).size()
+	}
+	c.stats.Evictions++
 }
 
-// Global cache instance
+// Stats returns a snapshot of the cache's counters (thread-safe)
+func (c *ShortlistCache) Stats() CacheStats {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	stats := c.stats
+	stats.Hits = c.hits.Load()
+	stats.Misses = c.misses.Load()
+	stats.Entries = c.tree.Len()
+	stats.MaxBytes = c.maxBytes
+	return stats
+}
+
+// FirstTurnCache is the global cache of shortlists by turn history prefix. Despite its name, it holds
+// the shortlists after any number of turns, not only the first
 var FirstTurnCache *ShortlistCache
 
-// InitCache initializes the global cache
+// InitCache initializes the global cache with the default budget
 func InitCache() {
 	FirstTurnCache = NewShortlistCache()
 }
+
+// InitCacheWithBudget initializes the global cache with a budget of maxBytes
+func InitCacheWithBudget(maxBytes int64) {
+	FirstTurnCache = NewShortlistCacheWithBudget(maxBytes)
+}
@@ -1,8 +1,12 @@
 package wordlegameengine
 
 import (
+	"errors"
+	"runtime"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestMakeCacheKey(t *testing.T) {
@@ -76,6 +80,71 @@ func TestMakeCacheKey_UniqueKeys(t *testing.T) {
 	}
 }
 
+func TestMakeTurnsKey(t *testing.T) {
+	tests := []struct {
+		name      string
+		guesses   []string
+		feedbacks []string
+		wantKey   string
+	}{
+		{"no turns", nil, nil, ""},
+		{"one turn", []string{"raise"}, []string{"-G---"}, "raise|-G---"},
+		{"two turns", []string{"raise", "clout"}, []string{"-G---", "--Y--"}, "raise|-G---;clout|--Y--"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			guesses := make([]Word, len(tt.guesses))
+			feedbacks := make([]Feedback, len(tt.feedbacks))
+			for i := range tt.guesses {
+				guesses[i] = mustNewWord(tt.guesses[i])
+				feedbacks[i], _ = ParseFeedback(tt.feedbacks[i])
+			}
+
+			if key := MakeTurnsKey(guesses, feedbacks); string(key) != tt.wantKey {
+				t.Errorf("MakeTurnsKey() = %q, want %q", key, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestShortlistCache_LongestPrefix(t *testing.T) {
+	guesses := []Word{mustNewWord("raise"), mustNewWord("clout"), mustNewWord("nymph")}
+	feedbacks := []Feedback{{Grey, Green, Grey, Grey, Grey}, {Grey, Grey, Yellow, Grey, Grey}, allGrey}
+
+	one := NewShortlist([]Word{mustNewWord("apple"), mustNewWord("crane")})
+	two := NewShortlist([]Word{mustNewWord("crane")})
+
+	tests := []struct {
+		name      string
+		cached    map[int]Shortlist // Prefix length to shortlist
+		wantTurns int
+		wantCount int
+	}{
+		{"nothing cached", nil, 0, 0},
+		{"first turn cached", map[int]Shortlist{1: one}, 1, 2},
+		{"longest prefix wins", map[int]Shortlist{1: one, 2: two}, 2, 1},
+		{"gap in prefixes", map[int]Shortlist{2: two}, 2, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := NewShortlistCache()
+			for n, shortlist := range tt.cached {
+				cache.Put(MakeTurnsKey(guesses[:n], feedbacks[:n]), shortlist)
+			}
+
+			turns, shortlist := cache.LongestPrefix(guesses, feedbacks)
+			if turns != tt.wantTurns {
+				t.Errorf("LongestPrefix() turns = %d, want %d", turns, tt.wantTurns)
+			}
+			if shortlist.Count() != tt.wantCount {
+				t.Errorf("LongestPrefix() shortlist length = %d, want %d", shortlist.Count(), tt.wantCount)
+			}
+		})
+	}
+}
+
 func TestShortlistCache_PutAndGet(t *testing.T) {
 	cache := NewShortlistCache()
 
@@ -83,7 +152,7 @@ func TestShortlistCache_PutAndGet(t *testing.T) {
 	word1, _ := NewWord("apple")
 	word2, _ := NewWord("crane")
 	word3, _ := NewWord("raise")
-	shortlist := []Word{word1, word2, word3}
+	shortlist := NewShortlist([]Word{word1, word2, word3})
 
 	// Create a cache key
 	guess, _ := NewWord("raise")
@@ -100,12 +169,12 @@ func TestShortlistCache_PutAndGet(t *testing.T) {
 	}
 
 	// Verify the retrieved shortlist matches
-	if len(retrieved) != len(shortlist) {
-		t.Errorf("Retrieved shortlist length = %d, want %d", len(retrieved), len(shortlist))
+	if retrieved.Count() != shortlist.Count() {
+		t.Errorf("Retrieved shortlist length = %d, want %d", retrieved.Count(), shortlist.Count())
 	}
-	for i, word := range shortlist {
-		if retrieved[i] != word {
-			t.Errorf("Retrieved shortlist[%d] = %v, want %v", i, retrieved[i], word)
+	for i, word := range shortlist.Words() {
+		if retrieved.Words()[i] != word {
+			t.Errorf("Retrieved shortlist[%d] = %v, want %v", i, retrieved.Words()[i], word)
 		}
 	}
 }
@@ -129,7 +198,7 @@ func TestShortlistCache_ModifyReturnedShortlist(t *testing.T) {
 	// Create a test shortlist
 	word1, _ := NewWord("apple")
 	word2, _ := NewWord("crane")
-	shortlist := []Word{word1, word2}
+	shortlist := NewShortlist([]Word{word1, word2})
 
 	guess, _ := NewWord("raise")
 	feedback, _ := ParseFeedback("-G---")
@@ -138,15 +207,14 @@ func TestShortlistCache_ModifyReturnedShortlist(t *testing.T) {
 	// Put into cache
 	cache.Put(key, shortlist)
 
-	// Get from cache and modify
+	// Get from cache and filter it, as a game would
 	retrieved, _ := cache.Get(key)
-	word3, _ := NewWord("slate")
-	retrieved = append(retrieved, word3)
+	retrieved = retrieved.Intersect(NewShortlist([]Word{word1}))
 
 	// Get again and verify original is unchanged
 	retrieved2, _ := cache.Get(key)
-	if len(retrieved2) != 2 {
-		t.Errorf("Cache shortlist was modified by external code: length = %d, want 2", len(retrieved2))
+	if retrieved2.Count() != 2 {
+		t.Errorf("Cache shortlist was modified by external code: length = %d, want 2", retrieved2.Count())
 	}
 }
 
@@ -166,7 +234,7 @@ func TestShortlistCache_ThreadSafety(t *testing.T) {
 			feedback, _ := ParseFeedback("-G---")
 			key := MakeCacheKey(guess, feedback)
 			word, _ := NewWord("apple")
-			cache.Put(key, []Word{word})
+			cache.Put(key, NewShortlist([]Word{word}))
 		}(i)
 	}
 
@@ -205,15 +273,239 @@ func TestShortlistCache_ReplaceOrInsert(t *testing.T) {
 	word2, _ := NewWord("crane")
 
 	// First insert
-	cache.Put(key, []Word{word1})
+	cache.Put(key, NewShortlist([]Word{word1}))
 
 	// Replace with new value
-	cache.Put(key, []Word{word2})
+	cache.Put(key, NewShortlist([]Word{word2}))
 
 	// Verify replacement
 	retrieved, _ := cache.Get(key)
-	if len(retrieved) != 1 || retrieved[0] != word2 {
-		t.Errorf("Expected replacement to work, got %v", retrieved)
+	if words := retrieved.Words(); len(words) != 1 || words[0] != word2 {
+		t.Errorf("Expected replacement to work, got %v", words)
+	}
+}
+
+func TestShortlistCache_Eviction(t *testing.T) {
+	shortlist := NewShortlist([]Word{mustNewWord("crane")})
+	keyA := MakeCacheKey(mustNewWord("raise"), allGrey)
+	keyB := MakeCacheKey(mustNewWord("slate"), allGrey)
+	keyC := MakeCacheKey(mustNewWord("crane"), allGrey)
+	entrySize := CacheEntry{Key: keyA, Shortlist: shortlist}.size()
+
+	// Room for two entries
+	cache := NewShortlistCacheWithBudget(2 * entrySize)
+	cache.Put(keyA, shortlist)
+	cache.Put(keyB, shortlist)
+
+	// Using A makes B the least recently used, so B is evicted for C
+	cache.Get(keyA)
+	cache.Put(keyC, shortlist)
+
+	if _, found := cache.Get(keyB); found {
+		t.Error("Expected the least recently used entry to be evicted")
+	}
+	for _, key := range []CacheKey{keyA, keyC} {
+		if _, found := cache.Get(key); !found {
+			t.Errorf("Expected %q to remain cached", key)
+		}
+	}
+
+	stats := cache.Stats()
+	if stats.Entries != 2 || stats.Evictions != 1 {
+		t.Errorf("Stats() entries = %d, evictions = %d, want 2, 1", stats.Entries, stats.Evictions)
+	}
+	if stats.Bytes != 2*entrySize || stats.MaxBytes != 2*entrySize {
+		t.Errorf("Stats() bytes = %d of %d, want %d of %d", stats.Bytes, stats.MaxBytes, 2*entrySize, 2*entrySize)
+	}
+}
+
+func TestShortlistCache_Eviction_SecondChance(t *testing.T) {
+	shortlist := NewShortlist([]Word{mustNewWord("crane")})
+	keys := []CacheKey{
+		MakeCacheKey(mustNewWord("raise"), allGrey),
+		MakeCacheKey(mustNewWord("slate"), allGrey),
+		MakeCacheKey(mustNewWord("crane"), allGrey),
+		MakeCacheKey(mustNewWord("moist"), allGrey),
+		MakeCacheKey(mustNewWord("pudgy"), allGrey),
+	}
+	entrySize := CacheEntry{Key: keys[0], Shortlist: shortlist}.size()
+
+	// Room for three entries
+	cache := NewShortlistCacheWithBudget(3 * entrySize)
+	for _, key := range keys[:3] {
+		cache.Put(key, shortlist)
+	}
+
+	// The two oldest were used, so the third is evicted first, and their reference bits cleared
+	cache.Get(keys[0])
+	cache.Get(keys[1])
+	cache.Put(keys[3], shortlist)
+	if _, found := cache.Get(keys[2]); found {
+		t.Errorf("Expected %q, the only unused entry, to be evicted", keys[2])
+	}
+
+	// keys[0] and keys[1] are used again by the Get below, which keys[3] is not
+	for _, key := range keys[:2] {
+		if _, found := cache.Get(key); !found {
+			t.Errorf("Expected %q to remain cached", key)
+		}
+	}
+	cache.Put(keys[4], shortlist)
+	if _, found := cache.Get(keys[3]); found {
+		t.Errorf("Expected %q, not used since it was added, to be evicted", keys[3])
+	}
+	if stats := cache.Stats(); stats.Entries != 3 || stats.Evictions != 2 {
+		t.Errorf("Stats() entries = %d, evictions = %d, want 3, 2", stats.Entries, stats.Evictions)
+	}
+}
+
+func TestShortlistCache_ReadsShareLock(t *testing.T) {
+	cache := NewShortlistCache()
+	key := MakeCacheKey(mustNewWord("raise"), allGrey)
+	cache.Put(key, FullShortlist())
+
+	// Lookups take only the read lock, so they proceed while another reader holds it
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		cache.Get(key)
+		cache.LongestPrefix([]Word{mustNewWord("raise")}, []Feedback{allGrey})
+		cache.GetOrCompute(key, func() (Shortlist, error) { return Shortlist{}, errors.New("not cached") })
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("cache hits blocked on a held read lock")
+	}
+	if stats := cache.Stats(); stats.Hits != 3 {
+		t.Errorf("Stats() hits = %d, want 3", stats.Hits)
+	}
+}
+
+func TestShortlistCache_Put_TooLargeForBudget(t *testing.T) {
+	cache := NewShortlistCacheWithBudget(10)
+	key := MakeCacheKey(mustNewWord("raise"), allGrey)
+	cache.Put(key, FullShortlist())
+
+	if _, found := cache.Get(key); found {
+		t.Error("Expected an entry larger than the budget not to be stored")
+	}
+	if stats := cache.Stats(); stats.Bytes != 0 || stats.Evictions != 0 {
+		t.Errorf("Stats() bytes = %d, evictions = %d, want 0, 0", stats.Bytes, stats.Evictions)
+	}
+}
+
+func TestShortlistCache_Stats(t *testing.T) {
+	cache := NewShortlistCacheWithBudget(0)
+	key := MakeCacheKey(mustNewWord("raise"), allGrey)
+	small := NewShortlist([]Word{mustNewWord("crane")})
+
+	cache.Get(key)
+	cache.Put(key, small)
+	cache.Get(key)
+	cache.LongestPrefix([]Word{mustNewWord("raise")}, []Feedback{allGrey})
+	cache.LongestPrefix([]Word{mustNewWord("slate")}, []Feedback{allGrey}) // Not a miss
+
+	// Replacing an entry accounts for the size of the new shortlist only
+	cache.Put(key, FullShortlist())
+
+	want := CacheStats{
+		Hits:    2,
+		Misses:  1,
+		Entries: 1,
+		Bytes:   CacheEntry{Key: key, Shortlist: FullShortlist()}.size(),
+	}
+	if got := cache.Stats(); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestShortlistCache_GetOrCompute(t *testing.T) {
+	cache := NewShortlistCacheWithBudget(0)
+	key := MakeCacheKey(mustNewWord("raise"), allGrey)
+	small := NewShortlist([]Word{mustNewWord("crane")})
+
+	computed := 0
+	compute := func() (Shortlist, error) {
+		computed++
+		return small, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		got, err := cache.GetOrCompute(key, compute)
+		if err != nil {
+			t.Fatalf("GetOrCompute() error = %v", err)
+		}
+		if got.Count() != 1 || !got.Contains(mustNewWord("crane")) {
+			t.Errorf("GetOrCompute() = %v, want [crane]", got.Words())
+		}
+	}
+	if computed != 1 {
+		t.Errorf("compute ran %d times, want 1", computed)
+	}
+	if stats := cache.Stats(); stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("Stats() hits = %d, misses = %d, want 1, 1", stats.Hits, stats.Misses)
+	}
+}
+
+func TestShortlistCache_GetOrCompute_Error(t *testing.T) {
+	cache := NewShortlistCacheWithBudget(0)
+	key := MakeCacheKey(mustNewWord("raise"), allGrey)
+	errCompute := errors.New("compute failed")
+
+	_, err := cache.GetOrCompute(key, func() (Shortlist, error) { return Shortlist{}, errCompute })
+	if !errors.Is(err, errCompute) {
+		t.Errorf("GetOrCompute() error = %v, want %v", err, errCompute)
+	}
+	if _, found := cache.Get(key); found {
+		t.Error("Expected a failed computation not to be cached")
+	}
+}
+
+func TestShortlistCache_GetOrCompute_Concurrent(t *testing.T) {
+	const numCallers = 50
+	cache := NewShortlistCacheWithBudget(0)
+	key := MakeCacheKey(mustNewWord("raise"), allGrey)
+	small := NewShortlist([]Word{mustNewWord("crane")})
+
+	var computed atomic.Int32
+	release := make(chan struct{})
+	compute := func() (Shortlist, error) {
+		computed.Add(1)
+		<-release
+		return small, nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]Shortlist, numCallers)
+	for i := 0; i < numCallers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = cache.GetOrCompute(key, compute)
+		}(i)
+	}
+
+	// Hold the computation until every other caller is waiting on it
+	for cache.Stats().Deduplicated < numCallers-1 {
+		runtime.Gosched()
+	}
+	close(release)
+	wg.Wait()
+
+	if n := computed.Load(); n != 1 {
+		t.Errorf("compute ran %d times, want 1", n)
+	}
+	for i, got := range results {
+		if !got.Contains(mustNewWord("crane")) {
+			t.Errorf("caller %d got %v, want [crane]", i, got.Words())
+		}
+	}
+	if stats := cache.Stats(); stats.Misses != 1 || stats.Deduplicated != numCallers-1 {
+		t.Errorf("Stats() misses = %d, deduplicated = %d, want 1, %d", stats.Misses, stats.Deduplicated, numCallers-1)
 	}
 }
 
@@ -280,9 +572,9 @@ func TestInitCache(t *testing.T) {
 	key := MakeCacheKey(guess, feedback)
 	word, _ := NewWord("apple")
 
-	FirstTurnCache.Put(key, []Word{word})
+	FirstTurnCache.Put(key, NewShortlist([]Word{word}))
 	retrieved, found := FirstTurnCache.Get(key)
-	if !found || len(retrieved) != 1 {
+	if !found || retrieved.Count() != 1 {
 		t.Error("Global cache should be functional after InitCache()")
 	}
 }
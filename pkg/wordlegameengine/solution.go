@@ -1,64 +1,76 @@
 package wordlegameengine
 
-import "fmt"
-
 type Solution Word
 
 func errNotInSolutions(s string) error {
-	return fmt.Errorf("%q not in allowed solutions", s)
+	return &ValidationError{Field: FieldSolution, Value: s, Position: -1, Err: ErrNotInWordlist}
 }
 
 func NewSolution(s string) (Solution, error) {
 	var sol Solution
-	if err := parseWord(s, sol[:]); err != nil {
+	if err := parseWord(s, sol[:], FieldSolution); err != nil {
 		return Solution{}, err
 	}
 	return sol, nil
 }
 
 func (s Solution) String() string {
-	return string(s[:])
+	return Word(s).String()
 }
 
+// Validate checks s against the default engine's allowed solutions; see Engine.ValidateSolution
 func (s *Solution) Validate() error {
-	str := s.String()
-	if err := validateCharacters(str); err != nil {
-		return err
-	}
-	if !isInWordlist(str, AllowedSolutions) {
-		return errNotInSolutions(str)
-	}
-	return nil
+	return Default().ValidateSolution(*s)
 }
 
 type TileColor int8
 
 const (
-	Grey TileColor = iota
+	// NoTile pads a Feedback beyond the length of its word
+	NoTile TileColor = iota
+	Grey
 	Yellow
 	Green
 )
 
-type Feedback [WordLength]TileColor
+// Feedback holds one tile per letter of the guess, padded with NoTile
+type Feedback [MaxWordLength]TileColor
+
+// MaxFeedbacks is the number of distinct feedback patterns for the longest words
+const MaxFeedbacks = 6561 // 3^MaxWordLength
+
+// NumFeedbacks returns the number of distinct feedback patterns for words of the given length,
+// 3^length
+func NumFeedbacks(length int) int {
+	n := 1
+	for i := 0; i < length; i++ {
+		n *= 3
+	}
+	return n
+}
 
+// CheckGuess scores guess against the solution. Both must have the same length
 func (s *Solution) CheckGuess(guess Word) Feedback {
 	var feedback Feedback
-	var used [WordLength]bool
+	var used [MaxWordLength]bool
+	n := Word(*s).Len()
 
 	// First pass: mark greens
-	for i := 0; i < WordLength; i++ {
+	for i := 0; i < n; i++ {
 		if guess[i] == s[i] {
 			feedback[i] = Green
 			used[i] = true
+		} else {
+			feedback[i] = Grey
 		}
 	}
 
 	// Second pass: mark yellows
-	for i := 0; i < WordLength; i++ {
+	for i := 0; i < n; i++ {
 		if feedback[i] == Green {
 			continue
 		}
-		for j := 0; j < WordLength; j++ {
+		for j := 0; j < n; j++ {
 			if !used[j] && guess[i] == s[j] {
 				feedback[i] = Yellow
 				used[j] = true
@@ -70,12 +82,22 @@ func (s *Solution) CheckGuess(guess Word) Feedback {
 	return feedback
 }
 
+// Len returns the number of tiles, i.e. the length of the word the feedback is for
+func (f Feedback) Len() int {
+	for i, color := range f {
+		if color == NoTile {
+			return i
+		}
+	}
+	return MaxWordLength
+}
+
 func (f Feedback) String() string {
 	// Convert each TileColor to its character representation:
 	// Green -> 'G', Yellow -> 'Y', Grey -> '-'
-	result := make([]byte, WordLength)
-	for i, color := range f {
-		switch color {
+	result := make([]byte, f.Len())
+	for i := range result {
+		switch f[i] {
 		case Green:
 			result[i] = 'G'
 		case Yellow:
@@ -87,13 +109,44 @@ func (f Feedback) String() string {
 	return string(result)
 }
 
+// Encode packs the feedback into a base-3 number in [0, NumFeedbacks(f.Len())), first tile most
+// significant
+func (f Feedback) Encode() uint16 {
+	var code uint16
+	for _, color := range f[:f.Len()] {
+		code = code*3 + uint16(color-Grey)
+	}
+	return code
+}
+
+// DecodeFeedback is the inverse of Feedback.Encode, for feedback of the given length
+func DecodeFeedback(code uint16, length int) Feedback {
+	var f Feedback
+	for i := length - 1; i >= 0; i-- {
+		f[i] = Grey + TileColor(code%3)
+		code /= 3
+	}
+	return f
+}
+
+// AllGreen reports whether the feedback marks every letter as correct
+func (f Feedback) AllGreen() bool {
+	n := f.Len()
+	for _, color := range f[:n] {
+		if color != Green {
+			return false
+		}
+	}
+	return n > 0
+}
+
 func ParseFeedback(s string) (Feedback, error) {
 	var f Feedback
-	if len(s) != WordLength {
-		return f, fmt.Errorf("feedback must be %d characters", WordLength)
+	if len(s) < MinWordLength || len(s) > MaxWordLength {
+		return f, errInvalidLength(FieldFeedback, s, 0)
 	}
-	for i, c := range s {
-		switch c {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
 		case 'G', 'g':
 			f[i] = Green
 		case 'Y', 'y':
@@ -101,7 +154,7 @@ func ParseFeedback(s string) (Feedback, error) {
 		case '-', 'X', 'x', 'B', 'b':
 			f[i] = Grey
 		default:
-			return f, fmt.Errorf("invalid feedback character: %q", c)
+			return Feedback{}, errInvalidCharacter(FieldFeedback, s, i)
 		}
 	}
 	return f, nil
@@ -4,6 +4,9 @@ import (
 	"testing"
 )
 
+// allGrey is the feedback for a five-letter guess sharing no letters with the solution
+var allGrey = Feedback{Grey, Grey, Grey, Grey, Grey}
+
 func TestSolution_CheckGuess(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -100,8 +103,20 @@ func TestParseFeedback(t *testing.T) {
 			wantErr: true,
 		},
 		{
-			name:    "invalid length - too long",
+			name:    "valid input of four tiles",
+			input:   "G-Y-",
+			want:    Feedback{Green, Grey, Yellow, Grey},
+			wantErr: false,
+		},
+		{
+			name:    "valid input of seven tiles",
 			input:   "-------",
+			want:    Feedback{Grey, Grey, Grey, Grey, Grey, Grey, Grey},
+			wantErr: false,
+		},
+		{
+			name:    "invalid length - too long",
+			input:   "---------",
 			want:    Feedback{},
 			wantErr: true,
 		},
@@ -133,11 +148,98 @@ func TestParseFeedback(t *testing.T) {
 	}
 }
 
+func TestFeedback_AllGreen(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"GGGGG", true},
+		{"GGGGY", false},
+		{"-----", false},
+		{"GGG-G", false},
+	}
+
+	for _, tt := range tests {
+		f, err := ParseFeedback(tt.input)
+		if err != nil {
+			t.Fatalf("ParseFeedback(%q) error: %v", tt.input, err)
+		}
+		if got := f.AllGreen(); got != tt.want {
+			t.Errorf("ParseFeedback(%q).AllGreen() = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestFeedback_Encode(t *testing.T) {
+	tests := []struct {
+		input string
+		want  uint16
+	}{
+		{"-----", 0},
+		{"----Y", 1},
+		{"----G", 2},
+		{"---Y-", 3},
+		{"Y----", 81},
+		{"GGGGG", uint16(NumFeedbacks(5) - 1)},
+		{"GGGG", uint16(NumFeedbacks(4) - 1)},
+		{"GGGGGGGG", MaxFeedbacks - 1},
+	}
+
+	for _, tt := range tests {
+		f, err := ParseFeedback(tt.input)
+		if err != nil {
+			t.Fatalf("ParseFeedback(%q) error: %v", tt.input, err)
+		}
+		if got := f.Encode(); got != tt.want {
+			t.Errorf("ParseFeedback(%q).Encode() = %d, want %d", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeFeedback(t *testing.T) {
+	for length := MinWordLength; length <= MaxWordLength; length++ {
+		for code := 0; code < NumFeedbacks(length); code++ {
+			f := DecodeFeedback(uint16(code), length)
+			if f.Len() != length {
+				t.Fatalf("DecodeFeedback(%d, %d).Len() = %d", code, length, f.Len())
+			}
+			if got := f.Encode(); got != uint16(code) {
+				t.Errorf("DecodeFeedback(%d, %d).Encode() = %d, want %d", code, length, got, code)
+			}
+		}
+	}
+}
+
+func TestSolution_CheckGuess_WordLengths(t *testing.T) {
+	tests := []struct {
+		solution string
+		guess    string
+		want     string
+	}{
+		{"lamp", "palm", "YGYY"},
+		{"orange", "garden", "YYY-YY"},
+		{"absolute", "abstract", "GGGY----"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.solution, func(t *testing.T) {
+			want, err := ParseFeedback(tt.want)
+			if err != nil {
+				t.Fatalf("ParseFeedback(%q) error = %v", tt.want, err)
+			}
+			solution := mustNewSolution(tt.solution)
+			if got := solution.CheckGuess(mustNewWord(tt.guess)); got != want {
+				t.Errorf("CheckGuess(%q) = %s, want %s", tt.guess, feedbackString(got), feedbackString(want))
+			}
+		})
+	}
+}
+
 func feedbackString(f Feedback) string {
 	colors := []rune{'⬜', '🟨', '🟩'}
-	result := make([]rune, WordLength)
-	for i, c := range f {
-		result[i] = colors[c]
+	result := make([]rune, f.Len())
+	for i, c := range f[:f.Len()] {
+		result[i] = colors[c-Grey]
 	}
 	return string(result)
 }
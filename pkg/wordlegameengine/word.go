@@ -1,74 +1,141 @@
 package wordlegameengine
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
-	"sort"
 )
 
-const WordLength = 5
+// Supported word lengths. The standard wordlists have DefaultWordLength letters
+const (
+	MinWordLength     = 4
+	MaxWordLength     = 8
+	DefaultWordLength = 5
+)
+
+// Word is a word of MinWordLength to MaxWordLength letters, padded with zero bytes. Its length is
+// given by Len; an engine accepts only words of its own length
+type Word [MaxWordLength]byte
+
+// Sentinel errors for checking the reason a word was rejected with errors.Is
+var (
+	ErrInvalidLength    = errors.New("invalid length")
+	ErrInvalidCharacter = errors.New("invalid character")
+	ErrNotInWordlist    = errors.New("not in wordlist")
+)
+
+// Fields reported in ValidationError.Field
+const (
+	FieldGuess    = "guess"
+	FieldSolution = "solution"
+	FieldFeedback = "feedback"
+)
+
+// ValidationError describes a rejected guess, solution or feedback string. It unwraps to
+// one of the sentinel errors, so callers can use errors.Is for the reason and errors.As for details
+type ValidationError struct {
+	Field    string // FieldGuess, FieldSolution or FieldFeedback
+	Value    string // The offending input
+	Position int    // Index of the offending character, or -1 if not about a single character
+	Length   int    // The required length, for ErrInvalidLength; 0 means any supported length
+	Err      error  // The sentinel error giving the reason
+}
 
-type Word [WordLength]byte
+func (e *ValidationError) Error() string {
+	switch {
+	case e.Field == FieldFeedback && e.Err == ErrInvalidLength:
+		return fmt.Sprintf("feedback %q must be %s characters", e.Value, e.lengthString())
+	case e.Field == FieldFeedback && e.Err == ErrInvalidCharacter:
+		return fmt.Sprintf("invalid feedback character %q at position %d in %q", e.Value[e.Position], e.Position, e.Value)
+	case e.Err == ErrInvalidLength:
+		return fmt.Sprintf("%q must be %s letters", e.Value, e.lengthString())
+	case e.Err == ErrInvalidCharacter:
+		return fmt.Sprintf("%q must contain only lowercase a-z", e.Value)
+	case e.Err == ErrNotInWordlist && e.Field == FieldSolution:
+		return fmt.Sprintf("%q not in allowed solutions", e.Value)
+	case e.Err == ErrNotInWordlist:
+		return fmt.Sprintf("%q not in allowed guesses", e.Value)
+	case e.Err == ErrGuessNotSolution:
+		return fmt.Sprintf("%q not in allowed solutions, which are the only words that may be guessed", e.Value)
+	case e.Err == ErrRepeatedGuess:
+		return fmt.Sprintf("%q has already been played", e.Value)
+	default:
+		return fmt.Sprintf("%s %q: %v", e.Field, e.Value, e.Err)
+	}
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
 
-func errInvalidLength(s string) error {
-	return fmt.Errorf("%q must be %d letters", s, WordLength)
+func (e *ValidationError) lengthString() string {
+	if e.Length == 0 {
+		return fmt.Sprintf("%d to %d", MinWordLength, MaxWordLength)
+	}
+	return fmt.Sprint(e.Length)
+}
+
+// errInvalidLength reports s not having the given length, or any supported length if length is 0
+func errInvalidLength(field, s string, length int) error {
+	return &ValidationError{Field: field, Value: s, Position: -1, Length: length, Err: ErrInvalidLength}
 }
 
-func errInvalidCharacter(s string) error {
-	return fmt.Errorf("%q must contain only lowercase a-z", s)
+func errInvalidCharacter(field, s string, pos int) error {
+	return &ValidationError{Field: field, Value: s, Position: pos, Err: ErrInvalidCharacter}
 }
 
 func errNotInWordlist(s string) error {
-	return fmt.Errorf("%q not in allowed guesses", s)
+	return &ValidationError{Field: FieldGuess, Value: s, Position: -1, Err: ErrNotInWordlist}
 }
 
 func NewWord(s string) (Word, error) {
 	var w Word
-	if err := parseWord(s, w[:]); err != nil {
+	if err := parseWord(s, w[:], FieldGuess); err != nil {
 		return Word{}, err
 	}
 	return w, nil
 }
 
+// Len returns the number of letters in the word
+func (w Word) Len() int {
+	for i, b := range w {
+		if b == 0 {
+			return i
+		}
+	}
+	return MaxWordLength
+}
+
 func (w Word) String() string {
-	return string(w[:])
+	return string(w[:w.Len()])
+}
+
+// Compare orders words alphabetically, returning -1, 0 or +1
+func (w Word) Compare(other Word) int {
+	return bytes.Compare(w[:], other[:])
 }
 
+// Validate checks w against the default engine's allowed guesses; see Engine.ValidateWord
 func (w *Word) Validate() error {
-	s := w.String()
-	if err := validateCharacters(s); err != nil {
-		return err
-	}
-	if !isInWordlist(s, AllowedGuesses) {
-		return errNotInWordlist(s)
-	}
-	return nil
+	return Default().ValidateWord(*w)
 }
 
-func parseWord(s string, dest []byte) error {
-	if len(s) != WordLength {
-		return errInvalidLength(s)
+func parseWord(s string, dest []byte, field string) error {
+	if len(s) < MinWordLength || len(s) > MaxWordLength {
+		return errInvalidLength(field, s, 0)
 	}
-	for i := 0; i < len(s); i++ {
-		if s[i] < 'a' || s[i] > 'z' {
-			return errInvalidCharacter(s)
-		}
+	if err := validateCharacters(s, field); err != nil {
+		return err
 	}
 	copy(dest, s)
 	return nil
 }
 
-func validateCharacters(s string) error {
+func validateCharacters(s string, field string) error {
 	for i := 0; i < len(s); i++ {
 		if s[i] < 'a' || s[i] > 'z' {
-			return errInvalidCharacter(s)
+			return errInvalidCharacter(field, s, i)
 		}
 	}
 	return nil
 }
-
-func isInWordlist(s string, wordlist []Word) bool {
-	idx := sort.Search(len(wordlist), func(i int) bool {
-		return wordlist[i].String() >= s
-	})
-	return idx < len(wordlist) && wordlist[idx].String() == s
-}
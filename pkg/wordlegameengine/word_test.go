@@ -1,6 +1,7 @@
 package wordlegameengine
 
 import (
+	"errors"
 	"strings"
 	"testing"
 )
@@ -15,9 +16,11 @@ func TestNewWord(t *testing.T) {
 		{"valid word", "hello", false, ""},
 		{"valid word all a", "aaaaa", false, ""},
 		{"valid word all z", "zzzzz", false, ""},
-		{"too short", "hell", true, "must be 5 letters"},
-		{"too long", "helloo", true, "must be 5 letters"},
-		{"empty string", "", true, "must be 5 letters"},
+		{"valid four letters", "hell", false, ""},
+		{"valid eight letters", "hellhole", false, ""},
+		{"too short", "hel", true, "must be 4 to 8 letters"},
+		{"too long", "helloooos", true, "must be 4 to 8 letters"},
+		{"empty string", "", true, "must be 4 to 8 letters"},
 		{"uppercase letter", "Hello", true, "must contain only lowercase a-z"},
 		{"contains number", "hell0", true, "must contain only lowercase a-z"},
 		{"contains space", "hell ", true, "must contain only lowercase a-z"},
@@ -66,6 +69,26 @@ func TestWord_String(t *testing.T) {
 	}
 }
 
+func TestWord_Compare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"apple", "crane", -1},
+		{"crane", "apple", 1},
+		{"crane", "crane", 0},
+		{"crane", "crank", -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			if got := mustNewWord(tt.a).Compare(mustNewWord(tt.b)); got != tt.want {
+				t.Errorf("%q.Compare(%q) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestWord_Validate(t *testing.T) {
 	// Set up test wordlist
 	oldGuesses := AllowedGuesses
@@ -88,7 +111,8 @@ func TestWord_Validate(t *testing.T) {
 		{"valid word in list (last)", mustNewWord("eager"), false, ""},
 		{"valid word not in list", mustNewWord("zebra"), true, "not in allowed guesses"},
 		{"invalid uppercase", Word{'H', 'e', 'l', 'l', 'o'}, true, "must contain only lowercase a-z"},
-		{"invalid null byte", Word{0, 'e', 'l', 'l', 'o'}, true, `"\x00ello" must contain only lowercase a-z`},
+		{"null byte ends the word", Word{'h', 'e', 'l', 0, 'o'}, true, `"hel" must be 5 letters`},
+		{"wrong length", mustNewWord("hello"[:4]), true, `"hell" must be 5 letters`},
 		{"invalid number", Word{'h', 'e', 'l', 'l', '0'}, true, "must contain only lowercase a-z"},
 	}
 
@@ -105,6 +129,92 @@ func TestWord_Validate(t *testing.T) {
 	}
 }
 
+func TestValidationError(t *testing.T) {
+	oldGuesses := AllowedGuesses
+	defer func() { AllowedGuesses = oldGuesses }()
+	AllowedGuesses = []Word{mustNewWord("apple")}
+
+	tests := []struct {
+		name         string
+		run          func() error
+		wantSentinel error
+		wantField    string
+		wantValue    string
+		wantPosition int
+	}{
+		{
+			name:         "word too short",
+			run:          func() error { _, err := NewWord("abc"); return err },
+			wantSentinel: ErrInvalidLength,
+			wantField:    FieldGuess,
+			wantValue:    "abc",
+			wantPosition: -1,
+		},
+		{
+			name:         "word with bad character",
+			run:          func() error { _, err := NewWord("ab1de"); return err },
+			wantSentinel: ErrInvalidCharacter,
+			wantField:    FieldGuess,
+			wantValue:    "ab1de",
+			wantPosition: 2,
+		},
+		{
+			name:         "word not in guesses",
+			run:          func() error { w := mustNewWord("zebra"); return w.Validate() },
+			wantSentinel: ErrNotInWordlist,
+			wantField:    FieldGuess,
+			wantValue:    "zebra",
+			wantPosition: -1,
+		},
+		{
+			name:         "solution with bad character",
+			run:          func() error { _, err := NewSolution("Crane"); return err },
+			wantSentinel: ErrInvalidCharacter,
+			wantField:    FieldSolution,
+			wantValue:    "Crane",
+			wantPosition: 0,
+		},
+		{
+			name:         "feedback too long",
+			run:          func() error { _, err := ParseFeedback("---------"); return err },
+			wantSentinel: ErrInvalidLength,
+			wantField:    FieldFeedback,
+			wantValue:    "---------",
+			wantPosition: -1,
+		},
+		{
+			name:         "feedback with bad character",
+			run:          func() error { _, err := ParseFeedback("-G-!-"); return err },
+			wantSentinel: ErrInvalidCharacter,
+			wantField:    FieldFeedback,
+			wantValue:    "-G-!-",
+			wantPosition: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.run()
+			if !errors.Is(err, tt.wantSentinel) {
+				t.Fatalf("error = %v, want errors.Is(err, %v)", err, tt.wantSentinel)
+			}
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("error = %v, want *ValidationError", err)
+			}
+			if validationErr.Field != tt.wantField {
+				t.Errorf("Field = %q, want %q", validationErr.Field, tt.wantField)
+			}
+			if validationErr.Value != tt.wantValue {
+				t.Errorf("Value = %q, want %q", validationErr.Value, tt.wantValue)
+			}
+			if validationErr.Position != tt.wantPosition {
+				t.Errorf("Position = %d, want %d", validationErr.Position, tt.wantPosition)
+			}
+		})
+	}
+}
+
 func mustNewWord(s string) Word {
 	w, err := NewWord(s)
 	if err != nil {
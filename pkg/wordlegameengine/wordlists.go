@@ -2,52 +2,241 @@ package wordlegameengine
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
+	"slices"
+	"strings"
+
+	"github.com/sam-bee/wordle-game-engine/data"
+)
+
+// Names of the wordlist files in a data directory or fs.FS
+const (
+	GuessesFile   = "allowed-guesses.txt"
+	SolutionsFile = "allowed-solutions.txt"
 )
 
+var ErrInvalidWordlist = errors.New("invalid wordlist")
+
 var AllowedGuesses []Word
 var AllowedSolutions []Word
 
+// WordlistLine is a line of a wordlist file that was reported
+type WordlistLine struct {
+	Line int    // 1-based line number
+	Text string // The line, with comments and surrounding whitespace removed
+}
+
+// WordlistReport lists the problems found while reading a wordlist. Invalid lines are left out of
+// the list; the other problems are corrected: words are lowercased, duplicates dropped and the list
+// sorted, as lookups use a binary search
+type WordlistReport struct {
+	Name         string
+	Words        int            // Number of words in the list as loaded
+	Length       int            // Length of the words, set by the first valid word
+	Invalid      []WordlistLine // Not 4 to 8 letters a-z in either case, or not of the list's length
+	NonLowercase []WordlistLine
+	Duplicates   []WordlistLine
+	Unsorted     []WordlistLine // Words that come before the previous word
+}
+
+// OK reports whether the wordlist had no problems
+func (r *WordlistReport) OK() bool {
+	return len(r.Invalid) == 0 && len(r.NonLowercase) == 0 && len(r.Duplicates) == 0 && len(r.Unsorted) == 0
+}
+
+// Err returns an error wrapping ErrInvalidWordlist if the wordlist had invalid lines
+func (r *WordlistReport) Err() error {
+	if len(r.Invalid) == 0 {
+		return nil
+	}
+	first := r.Invalid[0]
+	return fmt.Errorf("%s: %w: %d invalid lines, first %q at line %d",
+		r.Name, ErrInvalidWordlist, len(r.Invalid), first.Text, first.Line)
+}
+
+func (r *WordlistReport) String() string {
+	if r.OK() {
+		return fmt.Sprintf("%s: %d words", r.Name, r.Words)
+	}
+	return fmt.Sprintf("%s: %d words; %d invalid, %d non-lowercase, %d duplicate and %d unsorted lines",
+		r.Name, r.Words, len(r.Invalid), len(r.NonLowercase), len(r.Duplicates), len(r.Unsorted))
+}
+
+// WordlistsReport lists the problems found while reading a pair of wordlists
+type WordlistsReport struct {
+	Guesses   WordlistReport
+	Solutions WordlistReport
+	// MissingSolutions are solutions that are not allowed guesses, so could never be guessed
+	MissingSolutions []Word
+}
+
+// OK reports whether neither wordlist had problems
+func (r *WordlistsReport) OK() bool {
+	return r.Guesses.OK() && r.Solutions.OK() && len(r.MissingSolutions) == 0
+}
+
+// Err returns an error wrapping ErrInvalidWordlist if either wordlist had invalid lines
+func (r *WordlistsReport) Err() error {
+	return errors.Join(r.Guesses.Err(), r.Solutions.Err())
+}
+
+func (r *WordlistsReport) String() string {
+	return fmt.Sprintf("%s; %s; %d solutions missing from the guesses",
+		r.Guesses.String(), r.Solutions.String(), len(r.MissingSolutions))
+}
+
+// LoadDefaultWordlists loads the bundled wordlists, embedded in the binary, for the default engine
+func LoadDefaultWordlists() error {
+	_, err := LoadWordlistsFS(data.FS)
+	return err
+}
+
+// LoadWordlists loads the wordlists used by the default engine from dataDir
 func LoadWordlists(dataDir string) error {
-	var err error
+	if _, err := LoadWordlistsFS(os.DirFS(dataDir)); err != nil {
+		return fmt.Errorf("loading wordlists from %s: %w", dataDir, err)
+	}
+	return nil
+}
 
-	AllowedGuesses, err = loadWordlist(dataDir + "/allowed-guesses.txt")
+// LoadWordlistsDir loads the wordlists used by the default engine from dataDir, or the bundled
+// wordlists if dataDir is empty, and reports the problems found
+func LoadWordlistsDir(dataDir string) (*WordlistsReport, error) {
+	if dataDir == "" {
+		return LoadWordlistsFS(data.FS)
+	}
+	report, err := LoadWordlistsFS(os.DirFS(dataDir))
 	if err != nil {
-		return err
+		return report, fmt.Errorf("loading wordlists from %s: %w", dataDir, err)
 	}
+	return report, nil
+}
 
-	AllowedSolutions, err = loadWordlist(dataDir + "/allowed-solutions.txt")
+// LoadWordlistsFS loads the wordlists used by the default engine from the GuessesFile and
+// SolutionsFile in fsys, and reports the problems found. They are not loaded if either has
+// invalid lines
+func LoadWordlistsFS(fsys fs.FS) (*WordlistsReport, error) {
+	guesses, solutions, report, err := ReadWordlistsFS(fsys)
 	if err != nil {
-		return err
+		return report, err
 	}
+	AllowedGuesses, AllowedSolutions = guesses, solutions
+	return report, nil
+}
 
-	return nil
+// ReadWordlistsFS reads the GuessesFile and SolutionsFile from fsys; see ReadWordlists
+func ReadWordlistsFS(fsys fs.FS) ([]Word, []Word, *WordlistsReport, error) {
+	guessesFile, err := fsys.Open(GuessesFile)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer guessesFile.Close()
+
+	solutionsFile, err := fsys.Open(SolutionsFile)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer solutionsFile.Close()
+
+	return readWordlists(guessesFile, GuessesFile, solutionsFile, SolutionsFile)
+}
+
+// ReadWordlists reads a guess list and a solution list, and reports the problems found in them.
+// It returns an error wrapping ErrInvalidWordlist, along with the report, if either has invalid lines
+func ReadWordlists(guesses, solutions io.Reader) ([]Word, []Word, *WordlistsReport, error) {
+	return readWordlists(guesses, "guesses", solutions, "solutions")
 }
 
-func loadWordlist(filepath string) ([]Word, error) {
-	file, err := os.Open(filepath)
+func readWordlists(guessesReader io.Reader, guessesName string, solutionsReader io.Reader, solutionsName string) ([]Word, []Word, *WordlistsReport, error) {
+	report := &WordlistsReport{}
+
+	guesses, guessesReport, err := ReadWordlist(guessesReader, guessesName)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
-	defer file.Close()
+	report.Guesses = *guessesReport
 
-	var words []Word
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if line != "" {
-			word, err := NewWord(line)
-			if err != nil {
-				return nil, fmt.Errorf("invalid word %q in %s: %w", line, filepath, err)
-			}
-			words = append(words, word)
+	solutions, solutionsReport, err := ReadWordlist(solutionsReader, solutionsName)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	report.Solutions = *solutionsReport
+
+	if len(guesses) > 0 && len(solutions) > 0 && report.Guesses.Length != report.Solutions.Length {
+		return nil, nil, report, fmt.Errorf("%s has %d-letter words, %s has %d-letter words: %w",
+			guessesName, report.Guesses.Length, solutionsName, report.Solutions.Length, ErrMixedWordLengths)
+	}
+
+	for _, solution := range solutions {
+		if _, found := slices.BinarySearchFunc(guesses, solution, Word.Compare); !found {
+			report.MissingSolutions = append(report.MissingSolutions, solution)
 		}
 	}
 
+	if err := report.Err(); err != nil {
+		return nil, nil, report, err
+	}
+	return guesses, solutions, report, nil
+}
+
+// ReadWordlist reads a wordlist with one word per line. Surrounding whitespace, blank lines and
+// comments from '#' to the end of the line are ignored. The first valid word sets the length of
+// the list, and words of other lengths are reported as invalid. The words are returned sorted and
+// without duplicates, with a report of the problems found; name identifies the list in the report.
+// The error is only for failing to read r
+func ReadWordlist(r io.Reader, name string) ([]Word, *WordlistReport, error) {
+	report := &WordlistReport{Name: name}
+	seen := make(map[Word]bool)
+	var words []Word
+	var previous Word
+
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		line := WordlistLine{Line: lineNum, Text: text}
+
+		lower := strings.ToLower(text)
+		word, err := NewWord(lower)
+		if err != nil || (report.Length != 0 && word.Len() != report.Length) {
+			report.Invalid = append(report.Invalid, line)
+			continue
+		}
+		report.Length = word.Len()
+		if lower != text {
+			report.NonLowercase = append(report.NonLowercase, line)
+		}
+		if seen[word] {
+			report.Duplicates = append(report.Duplicates, line)
+			continue
+		}
+		if len(words) > 0 && word.Compare(previous) < 0 {
+			report.Unsorted = append(report.Unsorted, line)
+		}
+		seen[word] = true
+		words = append(words, word)
+		previous = word
+	}
 	if err := scanner.Err(); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return words, nil
+	if len(report.Unsorted) > 0 {
+		slices.SortFunc(words, Word.Compare)
+	}
+	report.Words = len(words)
+	return words, report, nil
+}
+
+// loadWordlists reads the GuessesFile and SolutionsFile from fsys
+func loadWordlists(fsys fs.FS) ([]Word, []Word, error) {
+	guesses, solutions, _, err := ReadWordlistsFS(fsys)
+	return guesses, solutions, err
 }
@@ -1,9 +1,15 @@
 package wordlegameengine
 
 import (
+	"errors"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
+	"testing/fstest"
 )
 
 func TestLoadWordlists(t *testing.T) {
@@ -128,3 +134,177 @@ func TestLoadWordlists(t *testing.T) {
 		}
 	})
 }
+
+func TestLoadWordlistsDir(t *testing.T) {
+	defer LoadWordlists("../../data")
+
+	report, err := LoadWordlistsDir("")
+	if err != nil || !report.OK() {
+		t.Errorf("LoadWordlistsDir(\"\") = %v, %v, want the bundled wordlists", report, err)
+	}
+	if len(AllowedSolutions) != 2309 {
+		t.Errorf("bundled wordlists have %d solutions, want 2309", len(AllowedSolutions))
+	}
+
+	if _, err := LoadWordlistsDir(t.TempDir()); err == nil {
+		t.Error("LoadWordlistsDir() error = nil, want error for a directory without wordlists")
+	}
+	if _, err := LoadWordlistsDir("../../data"); err != nil {
+		t.Errorf("LoadWordlistsDir() error = %v", err)
+	}
+}
+
+func TestLoadWordlistsFS(t *testing.T) {
+	oldGuesses, oldSolutions := AllowedGuesses, AllowedSolutions
+	defer func() { AllowedGuesses, AllowedSolutions = oldGuesses, oldSolutions }()
+
+	fsys := fstest.MapFS{
+		GuessesFile:   {Data: []byte("apple\nberry\ncrane\n")},
+		SolutionsFile: {Data: []byte("berry\n")},
+	}
+	report, err := LoadWordlistsFS(fsys)
+	if err != nil {
+		t.Fatalf("LoadWordlistsFS() error = %v", err)
+	}
+	if !report.OK() {
+		t.Errorf("report = %s, want no problems", report)
+	}
+	if len(AllowedGuesses) != 3 || len(AllowedSolutions) != 1 {
+		t.Errorf("loaded %d guesses, %d solutions, want 3, 1", len(AllowedGuesses), len(AllowedSolutions))
+	}
+
+	// Invalid lines are all reported, and nothing is loaded
+	fsys[GuessesFile] = &fstest.MapFile{Data: []byte("apple\nTOOLONG\nberry\nab1de\n")}
+	report, err = LoadWordlistsFS(fsys)
+	if !errors.Is(err, ErrInvalidWordlist) {
+		t.Errorf("LoadWordlistsFS() error = %v, want ErrInvalidWordlist", err)
+	}
+	if report == nil || len(report.Guesses.Invalid) != 2 {
+		t.Errorf("report = %v, want 2 invalid guesses", report)
+	}
+	if len(AllowedGuesses) != 3 {
+		t.Errorf("AllowedGuesses changed after a failed load")
+	}
+
+	delete(fsys, SolutionsFile)
+	if _, err := LoadWordlistsFS(fsys); err == nil {
+		t.Error("LoadWordlistsFS() error = nil, want error for missing solutions file")
+	}
+}
+
+func TestLoadDefaultWordlists(t *testing.T) {
+	oldGuesses, oldSolutions := AllowedGuesses, AllowedSolutions
+	defer func() { AllowedGuesses, AllowedSolutions = oldGuesses, oldSolutions }()
+
+	if err := LoadWordlists("../../data"); err != nil {
+		t.Fatalf("LoadWordlists() error = %v", err)
+	}
+	fromDir, fromDirSolutions := AllowedGuesses, AllowedSolutions
+
+	if err := LoadDefaultWordlists(); err != nil {
+		t.Fatalf("LoadDefaultWordlists() error = %v", err)
+	}
+	if !slices.Equal(AllowedGuesses, fromDir) || !slices.Equal(AllowedSolutions, fromDirSolutions) {
+		t.Error("LoadDefaultWordlists() differs from the data directory")
+	}
+}
+
+func TestReadWordlist(t *testing.T) {
+	input := `# Test wordlist
+apple
+  crane   # trailing comment
+
+Berry
+crane
+ab1de
+delta
+grapes
+`
+	words, report, err := ReadWordlist(strings.NewReader(input), "test.txt")
+	if err != nil {
+		t.Fatalf("ReadWordlist() error = %v", err)
+	}
+
+	want := []Word{mustNewWord("apple"), mustNewWord("berry"), mustNewWord("crane"), mustNewWord("delta")}
+	if !slices.Equal(words, want) {
+		t.Errorf("ReadWordlist() = %v, want %v", words, want)
+	}
+
+	tests := []struct {
+		name string
+		got  []WordlistLine
+		want []WordlistLine
+	}{
+		{"invalid", report.Invalid, []WordlistLine{{Line: 7, Text: "ab1de"}, {Line: 9, Text: "grapes"}}},
+		{"non-lowercase", report.NonLowercase, []WordlistLine{{Line: 5, Text: "Berry"}}},
+		{"duplicates", report.Duplicates, []WordlistLine{{Line: 6, Text: "crane"}}},
+		{"unsorted", report.Unsorted, []WordlistLine{{Line: 5, Text: "Berry"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !slices.Equal(tt.got, tt.want) {
+				t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+			}
+		})
+	}
+
+	if report.OK() || report.Words != 4 || report.Length != 5 {
+		t.Errorf("report = %s, length %d, want problems and 4 words of 5 letters", report, report.Length)
+	}
+	if !errors.Is(report.Err(), ErrInvalidWordlist) {
+		t.Errorf("Err() = %v, want ErrInvalidWordlist", report.Err())
+	}
+}
+
+func TestReadWordlists_MissingSolutions(t *testing.T) {
+	guesses, solutions, report, err := ReadWordlists(strings.NewReader("apple\ncrane\n"), strings.NewReader("crane\nslate\n"))
+	if err != nil {
+		t.Fatalf("ReadWordlists() error = %v", err)
+	}
+	if len(guesses) != 2 || len(solutions) != 2 {
+		t.Errorf("ReadWordlists() = %d guesses, %d solutions, want 2, 2", len(guesses), len(solutions))
+	}
+	if want := []Word{mustNewWord("slate")}; !slices.Equal(report.MissingSolutions, want) {
+		t.Errorf("MissingSolutions = %v, want %v", report.MissingSolutions, want)
+	}
+	if report.OK() {
+		t.Error("OK() = true, want false for a solution missing from the guesses")
+	}
+}
+
+func TestReadWordlists_WordLengths(t *testing.T) {
+	fsys := fstest.MapFS{
+		"6/" + GuessesFile:   {Data: []byte("danger\ngarden\norange\n")},
+		"6/" + SolutionsFile: {Data: []byte("garden\n")},
+		"4/" + GuessesFile:   {Data: []byte("lamp\npalm\n")},
+		"4/" + SolutionsFile: {Data: []byte("palm\n")},
+	}
+	for _, dir := range []string{"4", "6"} {
+		sub, err := fs.Sub(fsys, dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e, err := LoadEngineFS(sub)
+		if err != nil {
+			t.Fatalf("LoadEngineFS(%s) error = %v", dir, err)
+		}
+		if got := strconv.Itoa(e.WordLength()); got != dir {
+			t.Errorf("LoadEngineFS(%s).WordLength() = %s", dir, got)
+		}
+	}
+
+	_, _, _, err := ReadWordlists(strings.NewReader("danger\ngarden\n"), strings.NewReader("palm\n"))
+	if !errors.Is(err, ErrMixedWordLengths) {
+		t.Errorf("ReadWordlists() error = %v, want ErrMixedWordLengths", err)
+	}
+}
+
+func TestReadWordlistsFS_Bundled(t *testing.T) {
+	_, _, report, err := ReadWordlistsFS(os.DirFS("../../data"))
+	if err != nil {
+		t.Fatalf("ReadWordlistsFS() error = %v", err)
+	}
+	if !report.OK() {
+		t.Errorf("bundled wordlists report = %s, want no problems", report)
+	}
+}
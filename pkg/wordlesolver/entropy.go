@@ -0,0 +1,99 @@
+package wordlesolver
+
+import (
+	"math"
+
+	"github.com/sam-bee/wordle-game-engine/pkg/wordlegameengine"
+)
+
+// Entropy returns the expected information, in bits, from playing guess when the solution is
+// equally likely to be any word on the shortlist. It is the Shannon entropy of the partition of
+// the shortlist by the feedback each candidate would give
+func Entropy(guess wordlegameengine.Word, shortlist []wordlegameengine.Word) float64 {
+	buckets := make([]int, wordlegameengine.NumFeedbacks(guess.Len()))
+	partition(buckets, guess, shortlist)
+	return entropyScore(buckets, len(shortlist))
+}
+
+func entropyScore(buckets []int, total int) float64 {
+	entropy := 0.0
+	for _, count := range buckets {
+		if count == 0 {
+			continue
+		}
+		p := float64(count) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// minimaxScore is the size of the largest bucket: the worst-case shortlist size after the guess
+func minimaxScore(buckets []int, total int) float64 {
+	largest := 0
+	for _, count := range buckets {
+		largest = max(largest, count)
+	}
+	return float64(largest)
+}
+
+// expectedSizeScore is the expected shortlist size after the guess
+func expectedSizeScore(buckets []int, total int) float64 {
+	sumSquares := 0
+	for _, count := range buckets {
+		sumSquares += count * count
+	}
+	return float64(sumSquares) / float64(total)
+}
+
+// greensScore is the number of candidates for which the guess would reveal at least one green
+func greensScore(buckets []int, total int) float64 {
+	hits := 0
+	for code, count := range buckets {
+		if count == 0 {
+			continue
+		}
+		// Decoding at the maximum length only adds leading greys, which do not affect the count
+		for _, color := range wordlegameengine.DecodeFeedback(uint16(code), wordlegameengine.MaxWordLength) {
+			if color == wordlegameengine.Green {
+				hits += count
+				break
+			}
+		}
+	}
+	return float64(hits)
+}
+
+// partitionByTable is partition using a row of the precomputed feedback table, where the
+// shortlist is given by solution indexes
+func partitionByTable(buckets []int, row wordlegameengine.FeedbackRow, solutionIdxs []int) {
+	for _, idx := range solutionIdxs {
+		buckets[row.At(idx)]++
+	}
+}
+
+// shortlistIndexes returns the table's solution index for each shortlist word, or nil if there
+// is no table or it does not cover every word
+func shortlistIndexes(table *wordlegameengine.FeedbackTable, shortlist []wordlegameengine.Word) []int {
+	if table == nil {
+		return nil
+	}
+	idxs := make([]int, len(shortlist))
+	for i, word := range shortlist {
+		idx, ok := table.SolutionIndex(word)
+		if !ok {
+			return nil
+		}
+		idxs[i] = idx
+	}
+	return idxs
+}
+
+// partition counts the shortlist candidates into buckets, indexed by encoded feedback, by the
+// feedback guess would give against each of them. buckets must be zeroed and have room for every
+// feedback of the guess's length
+func partition(buckets []int, guess wordlegameengine.Word, shortlist []wordlegameengine.Word) {
+	for _, candidate := range shortlist {
+		solution := wordlegameengine.Solution(candidate)
+		buckets[solution.CheckGuess(guess).Encode()]++
+	}
+}
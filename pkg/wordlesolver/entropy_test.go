@@ -0,0 +1,110 @@
+package wordlesolver
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"testing"
+
+	"github.com/sam-bee/wordle-game-engine/pkg/wordlegameengine"
+)
+
+func TestMain(m *testing.M) {
+	if err := wordlegameengine.LoadWordlists("../../data"); err != nil {
+		fmt.Printf("Failed to load wordlists: %v\n", err)
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+func TestEntropy(t *testing.T) {
+	tests := []struct {
+		name      string
+		guess     string
+		shortlist []string
+		want      float64
+	}{
+		{
+			name:      "empty shortlist",
+			guess:     "crane",
+			shortlist: nil,
+			want:      0,
+		},
+		{
+			name:      "single candidate gives no information",
+			guess:     "crane",
+			shortlist: []string{"slate"},
+			want:      0,
+		},
+		{
+			name:      "two candidates split by the guess",
+			guess:     "crane",
+			shortlist: []string{"crane", "slate"},
+			want:      1,
+		},
+		{
+			name:      "two candidates the guess cannot split",
+			guess:     "xylyl",
+			shortlist: []string{"crane", "brace"},
+			want:      0,
+		},
+		{
+			name:      "four candidates all split",
+			guess:     "chant",
+			shortlist: []string{"scare", "share", "snare", "spare"},
+			want:      2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Entropy(mustNewWord(tt.guess), mustNewWords(tt.shortlist))
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Entropy(%q, %v) = %f, want %f", tt.guess, tt.shortlist, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScores(t *testing.T) {
+	// "chain" splits these into buckets of sizes 1, 1, 1 and 2 ("spare" and "stare")
+	shortlist := mustNewWords([]string{"scare", "share", "snare", "spare", "stare"})
+	buckets := make([]int, wordlegameengine.NumFeedbacks(5))
+	partition(buckets, mustNewWord("chain"), shortlist)
+	total := len(shortlist)
+
+	tests := []struct {
+		name  string
+		score func([]int, int) float64
+		want  float64
+	}{
+		{"entropy", entropyScore, math.Log2(5) - 0.4},
+		{"minimax", minimaxScore, 2},
+		{"expected size", expectedSizeScore, 7.0 / 5.0},
+		{"greens", greensScore, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.score(buckets, total); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("score = %f, want %f", got, tt.want)
+			}
+		})
+	}
+}
+
+func mustNewWord(s string) wordlegameengine.Word {
+	w, err := wordlegameengine.NewWord(s)
+	if err != nil {
+		panic(err)
+	}
+	return w
+}
+
+func mustNewWords(words []string) []wordlegameengine.Word {
+	result := make([]wordlegameengine.Word, len(words))
+	for i, s := range words {
+		result[i] = mustNewWord(s)
+	}
+	return result
+}
@@ -0,0 +1,28 @@
+package wordlesolver
+
+import "github.com/sam-bee/wordle-game-engine/pkg/wordlegameengine"
+
+// HardMode restricts a strategy to guesses allowed under Wordle's hard mode rules: revealed greens
+// must be reused in place, and revealed yellows must be included
+func HardMode(s Strategy) Strategy {
+	return &hardModeStrategy{inner: s}
+}
+
+type hardModeStrategy struct {
+	inner Strategy
+}
+
+func (s *hardModeStrategy) Name() string {
+	return "hard-" + s.inner.Name()
+}
+
+func (s *hardModeStrategy) Rank(game *wordlegameengine.Game) []ScoredGuess {
+	ranked := s.inner.Rank(game)
+	allowed := ranked[:0]
+	for _, scored := range ranked {
+		if wordlegameengine.CheckHardMode(scored.Guess, game.Guesses, game.Feedbacks) == nil {
+			allowed = append(allowed, scored)
+		}
+	}
+	return allowed
+}
@@ -0,0 +1,30 @@
+package wordlesolver
+
+import (
+	"testing"
+
+	"github.com/sam-bee/wordle-game-engine/pkg/wordlegameengine"
+)
+
+func TestHardMode_Rank(t *testing.T) {
+	game := wordlegameengine.NewAssistantGame()
+	if err := game.ReplayTurn(mustNewWord("slate"), wordlegameengine.Feedback{wordlegameengine.Grey, wordlegameengine.Grey,
+		wordlegameengine.Green, wordlegameengine.Grey, wordlegameengine.Green}); err != nil {
+		t.Fatal(err)
+	}
+
+	strategy := HardMode(EntropyStrategy)
+	if strategy.Name() != "hard-entropy" {
+		t.Errorf("Name() = %q, want hard-entropy", strategy.Name())
+	}
+
+	ranked := strategy.Rank(game)
+	if len(ranked) == 0 {
+		t.Fatal("Rank() returned no guesses")
+	}
+	for _, scored := range ranked {
+		if scored.Guess[2] != 'a' || scored.Guess[4] != 'e' {
+			t.Fatalf("Rank() included %q, which does not reuse the greens", scored.Guess.String())
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package wordlesolver
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/sam-bee/wordle-game-engine/pkg/wordlegameengine"
+)
+
+const numWorkers = 16
+
+var ErrUnknownStrategy = errors.New("unknown strategy")
+
+// ScoredGuess is a guess with its score from a strategy
+type ScoredGuess struct {
+	Guess       wordlegameengine.Word
+	Score       float64
+	InShortlist bool // Whether the guess could itself be the solution
+}
+
+// Strategy ranks the possible next guesses of a game, best first
+type Strategy interface {
+	Name() string
+	Rank(game *wordlegameengine.Game) []ScoredGuess
+}
+
+// Built-in strategies
+var (
+	// EntropyStrategy maximises the expected information from the feedback, in bits
+	EntropyStrategy Strategy = &partitionStrategy{name: "entropy", score: entropyScore}
+	// MinimaxStrategy minimises the worst-case shortlist size after the guess
+	MinimaxStrategy Strategy = &partitionStrategy{name: "minimax", score: minimaxScore, minimise: true}
+	// ExpectedSizeStrategy minimises the expected shortlist size after the guess
+	ExpectedSizeStrategy Strategy = &partitionStrategy{name: "expected-size", score: expectedSizeScore, minimise: true}
+	// GreensStrategy maximises the number of candidates for which the guess reveals a green
+	GreensStrategy Strategy = &partitionStrategy{name: "greens", score: greensScore}
+)
+
+// strategies holds every strategy that can be selected by name, including hard mode variants
+var strategies = func() map[string]Strategy {
+	m := make(map[string]Strategy)
+	for _, s := range []Strategy{EntropyStrategy, MinimaxStrategy, ExpectedSizeStrategy, GreensStrategy} {
+		m[s.Name()] = s
+		hard := HardMode(s)
+		m[hard.Name()] = hard
+	}
+	return m
+}()
+
+// StrategyByName returns the strategy with the given name, e.g. "minimax" or "hard-entropy"
+func StrategyByName(name string) (Strategy, error) {
+	s, ok := strategies[name]
+	if !ok {
+		return nil, fmt.Errorf("%q: %w", name, ErrUnknownStrategy)
+	}
+	return s, nil
+}
+
+// StrategyNames returns the names of all strategies, sorted
+func StrategyNames() []string {
+	names := make([]string, 0, len(strategies))
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Suggest returns the best n guesses for the game according to the strategy
+func Suggest(game *wordlegameengine.Game, strategy Strategy, n int) []ScoredGuess {
+	ranked := strategy.Rank(game)
+	if n < len(ranked) {
+		ranked = ranked[:n]
+	}
+	return ranked
+}
+
+// partitionStrategy scores every allowed guess of the game's engine by a function of how the guess would
+// partition the solution shortlist by feedback
+type partitionStrategy struct {
+	name     string
+	score    func(buckets []int, total int) float64
+	minimise bool // Lower scores are better
+}
+
+func (s *partitionStrategy) Name() string {
+	return s.name
+}
+
+// Rank scores the guesses in parallel and sorts them best first. Ties are broken in favour of
+// guesses on the shortlist, then alphabetically
+func (s *partitionStrategy) Rank(game *wordlegameengine.Game) []ScoredGuess {
+	engine := game.Engine()
+	shortlist := game.SolutionShortlist.Words()
+	guesses := engine.Guesses()
+	scored := make([]ScoredGuess, len(guesses))
+
+	if len(shortlist) == 0 {
+		return nil
+	}
+
+	// Use the precomputed feedback table when it covers the shortlist
+	table := engine.FeedbackTable()
+	solutionIdxs := shortlistIndexes(table, shortlist)
+
+	// Each worker takes every numWorkers'th guess
+	var wg sync.WaitGroup
+	for w := 0; w < numWorkers; w++ {
+		wg.Add(1)
+		go func(start int) {
+			defer wg.Done()
+			buckets := make([]int, engine.NumFeedbacks())
+			for i := start; i < len(guesses); i += numWorkers {
+				clear(buckets)
+				guessIdx, ok := 0, false
+				if solutionIdxs != nil {
+					guessIdx, ok = table.GuessIndex(guesses[i])
+				}
+				if ok {
+					partitionByTable(buckets, table.Row(guessIdx), solutionIdxs)
+				} else {
+					partition(buckets, guesses[i], shortlist)
+				}
+				scored[i] = ScoredGuess{
+					Guess: guesses[i],
+					Score: s.score(buckets, len(shortlist)),
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	for i := range scored {
+		scored[i].InShortlist = game.SolutionShortlist.Contains(scored[i].Guess)
+	}
+
+	sort.Slice(scored, func(i, j int) bool {
+		a, b := scored[i], scored[j]
+		if a.Score != b.Score {
+			return (a.Score < b.Score) == s.minimise
+		}
+		if a.InShortlist != b.InShortlist {
+			return a.InShortlist
+		}
+		return a.Guess.String() < b.Guess.String()
+	})
+	return scored
+}
@@ -0,0 +1,153 @@
+package wordlesolver
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/sam-bee/wordle-game-engine/pkg/wordlegameengine"
+)
+
+func TestSuggest(t *testing.T) {
+	game := wordlegameengine.NewGameWithShortlist(wordlegameengine.Solution{},
+		wordlegameengine.NewShortlist(mustNewWords([]string{"scare", "share", "snare", "spare", "stare"})))
+
+	suggestions := Suggest(game, EntropyStrategy, 3)
+	if len(suggestions) != 3 {
+		t.Fatalf("Suggest() returned %d guesses, want 3", len(suggestions))
+	}
+
+	// A guess containing c, h, n and p (or t) splits the five candidates completely
+	best := suggestions[0]
+	if want := math.Log2(5); math.Abs(best.Score-want) > 1e-9 {
+		t.Errorf("best Score = %f, want %f (%q)", best.Score, want, best.Guess.String())
+	}
+	for i := 1; i < len(suggestions); i++ {
+		if suggestions[i].Score > suggestions[i-1].Score {
+			t.Errorf("suggestions not sorted by score: %v", suggestions)
+		}
+	}
+}
+
+func TestSuggest_PrefersShortlistOnTies(t *testing.T) {
+	game := wordlegameengine.NewGameWithShortlist(wordlegameengine.Solution{},
+		wordlegameengine.NewShortlist(mustNewWords([]string{"crane"})))
+
+	suggestions := Suggest(game, EntropyStrategy, 1)
+	if len(suggestions) != 1 {
+		t.Fatalf("Suggest() returned %d guesses, want 1", len(suggestions))
+	}
+	if suggestions[0].Guess.String() != "crane" || !suggestions[0].InShortlist {
+		t.Errorf("Suggest() = %q, want the only remaining candidate crane", suggestions[0].Guess.String())
+	}
+}
+
+func TestStrategies_Rank(t *testing.T) {
+	game := wordlegameengine.NewGameWithShortlist(wordlegameengine.Solution{},
+		wordlegameengine.NewShortlist(mustNewWords([]string{"scare", "share", "snare", "spare", "stare"})))
+
+	tests := []struct {
+		strategy  Strategy
+		wantScore float64
+	}{
+		{EntropyStrategy, math.Log2(5)},
+		{MinimaxStrategy, 1},
+		{ExpectedSizeStrategy, 1},
+		{GreensStrategy, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.strategy.Name(), func(t *testing.T) {
+			ranked := tt.strategy.Rank(game)
+			if len(ranked) != len(wordlegameengine.AllowedGuesses) {
+				t.Fatalf("Rank() returned %d guesses, want %d", len(ranked), len(wordlegameengine.AllowedGuesses))
+			}
+			if math.Abs(ranked[0].Score-tt.wantScore) > 1e-9 {
+				t.Errorf("best Score = %f, want %f (%q)", ranked[0].Score, tt.wantScore, ranked[0].Guess.String())
+			}
+		})
+	}
+}
+
+func TestStrategies_MinimiseOrdering(t *testing.T) {
+	game := wordlegameengine.NewGameWithShortlist(wordlegameengine.Solution{},
+		wordlegameengine.NewShortlist(mustNewWords([]string{"scare", "share", "snare", "spare", "stare"})))
+
+	ranked := MinimaxStrategy.Rank(game)
+	for i := 1; i < len(ranked); i++ {
+		if ranked[i].Score < ranked[i-1].Score {
+			t.Fatalf("minimax ranking not ascending at %d: %f after %f", i, ranked[i].Score, ranked[i-1].Score)
+		}
+	}
+}
+
+func TestStrategyByName(t *testing.T) {
+	for _, name := range []string{"entropy", "minimax", "expected-size", "greens", "hard-entropy", "hard-minimax"} {
+		s, err := StrategyByName(name)
+		if err != nil {
+			t.Errorf("StrategyByName(%q) error = %v", name, err)
+			continue
+		}
+		if s.Name() != name {
+			t.Errorf("StrategyByName(%q).Name() = %q", name, s.Name())
+		}
+	}
+
+	if _, err := StrategyByName("random"); !errors.Is(err, ErrUnknownStrategy) {
+		t.Errorf("StrategyByName(\"random\") error = %v, want ErrUnknownStrategy", err)
+	}
+}
+
+func TestStrategyNames(t *testing.T) {
+	names := StrategyNames()
+	if len(names) != 8 {
+		t.Errorf("StrategyNames() = %v, want 8 names", names)
+	}
+	for i := 1; i < len(names); i++ {
+		if names[i] < names[i-1] {
+			t.Errorf("StrategyNames() not sorted: %v", names)
+		}
+	}
+}
+
+func TestStrategies_RankWithFeedbackTable(t *testing.T) {
+	shortlist := mustNewWords([]string{"scare", "share", "snare", "spare", "stare"})
+	game := wordlegameengine.NewGameWithShortlist(wordlegameengine.Solution{}, wordlegameengine.NewShortlist(shortlist))
+
+	oldTable := wordlegameengine.PrecomputedFeedback
+	defer func() { wordlegameengine.PrecomputedFeedback = oldTable }()
+
+	wordlegameengine.PrecomputedFeedback = nil
+	want := MinimaxStrategy.Rank(game)
+
+	wordlegameengine.PrecomputedFeedback = wordlegameengine.NewFeedbackTable(wordlegameengine.AllowedGuesses, shortlist)
+	got := MinimaxStrategy.Rank(game)
+
+	if len(got) != len(want) {
+		t.Fatalf("Rank() with table returned %d guesses, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Rank()[%d] with table = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestStrategies_RankWithEngine(t *testing.T) {
+	engine, err := wordlegameengine.NewEngine(
+		mustNewWords([]string{"apple", "grape", "lemon", "mango"}),
+		mustNewWords([]string{"apple", "grape", "mango"}))
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+
+	ranked := MinimaxStrategy.Rank(engine.NewAssistantGame())
+	if len(ranked) != 4 {
+		t.Fatalf("Rank() returned %d guesses, want one per allowed guess of the engine", len(ranked))
+	}
+	for _, scored := range ranked {
+		if scored.Guess.String() == "lemon" && scored.InShortlist {
+			t.Error("lemon is in the shortlist, but is not an allowed solution of the engine")
+		}
+	}
+}